@@ -3,10 +3,14 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"interpreter/internal/analysis"
+	"interpreter/internal/ast"
 	"interpreter/internal/evaluator"
 	"interpreter/internal/lexer"
+	_ "interpreter/internal/library/math"
 	"interpreter/internal/object"
 	"interpreter/internal/parser"
+	"interpreter/internal/token"
 	"os"
 )
 
@@ -21,6 +25,8 @@ func main() {
 			return
 		}
 		file(os.Args[1])
+	} else if len(os.Args) == 3 && os.Args[1] == "check" {
+		check(os.Args[2])
 	} else {
 		panic("wrong number of args")
 	}
@@ -32,7 +38,30 @@ func file(filename string) {
 	if err != nil {
 		panic("could not open file")
 	}
-	run(string(f), env)
+	run(filename, string(f), env)
+}
+
+// check reports the type errors found in filename without running it and
+// exits with a non-zero status if there are any.
+func check(filename string) {
+	f, err := os.ReadFile(filename)
+	if err != nil {
+		panic("could not open file")
+	}
+	source := string(f)
+	p := parser.New(lexer.NewFile(filename, source))
+	prog := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(source, p.Errors())
+		os.Exit(1)
+	}
+	diagnostics := analysis.Check(prog)
+	for _, d := range diagnostics {
+		printError(source, d.Token, d.Message)
+	}
+	if len(diagnostics) != 0 {
+		os.Exit(1)
+	}
 }
 
 func repl() {
@@ -44,24 +73,67 @@ func repl() {
 		if err != nil {
 			panic(fmt.Sprintf("could not read input: %s", err))
 		}
-		run(input, env)
+		run("", input, env)
 	}
 }
 
-func run(input string, env *object.Environment) {
-	l := lexer.New(input)
-	if l.HasError {
-		fmt.Println(l.HasError)
-		return
-	}
-	p := parser.New(l)
+func run(filename string, input string, env *object.Environment) {
+	// lexer errors are reported by the parser along with the syntax errors
+	p := parser.New(lexer.NewFile(filename, input))
 	prog := p.ParseProgram()
 	if len(p.Errors()) != 0 {
-		fmt.Println(p.Errors())
+		printParserErrors(input, p.Errors())
 		return
 	}
 	eval := evaluator.Eval(prog, env)
-	if eval != nil {
+	if err, ok := eval.(*object.Error); ok {
+		printError(sourceOf(filename, input, err.Token), err.Token, err.Inspect())
+		for _, frame := range err.Stack {
+			fmt.Printf("\tat %s\n", frame)
+		}
+	} else if echoes(prog, eval) {
 		fmt.Println(eval.Inspect())
 	}
 }
+
+// echoes reports whether run prints result, the value of prog. The null an
+// if statement evaluates to when no branch runs is not printed, only nulls
+// of expressions are.
+func echoes(prog *ast.Program, result object.Object) bool {
+	if result == nil {
+		return false
+	}
+	if _, ok := result.(*object.Null); !ok || len(prog.Statements) == 0 {
+		return true
+	}
+	_, ok := prog.Statements[len(prog.Statements)-1].(*ast.ExpressionStatement)
+	return ok
+}
+
+func printParserErrors(source string, errors []*parser.Error) {
+	for _, err := range errors {
+		printError(source, err.Token, err.Message)
+	}
+}
+
+// sourceOf returns the source tok was read from, which is input unless tok
+// comes from an imported file.
+func sourceOf(filename string, input string, tok token.Token) string {
+	if tok.Filename == filename {
+		return input
+	}
+	f, err := os.ReadFile(tok.Filename)
+	if err != nil {
+		return ""
+	}
+	return string(f)
+}
+
+// printError prints message with the position of tok and the line of source
+// tok was read from, marking the token with a caret.
+func printError(source string, tok token.Token, message string) {
+	fmt.Printf("%s: %s\n", tok.Position(), message)
+	if line := token.Highlight(source, tok); line != "" {
+		fmt.Println(line)
+	}
+}
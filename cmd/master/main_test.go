@@ -0,0 +1,34 @@
+package main
+
+import (
+	"interpreter/internal/evaluator"
+	"interpreter/internal/lexer"
+	"interpreter/internal/object"
+	"interpreter/internal/parser"
+	"testing"
+)
+
+func TestEchoes(t *testing.T) {
+	tests := []struct {
+		input string
+		echo  bool
+	}{
+		{`var x = 1; if x == 1 { 2; }`, true},
+		{`var x = 1; if x == 2 { 2; }`, false},
+		{`var x = 1; if x == 1 { var y = 2; }`, false},
+		{`var x = 1; x;`, true},
+		{`var x = 1;`, false},
+		{`var x = if false { 1; }; x;`, true},
+	}
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		prog := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parse errors for %q: %v", tt.input, p.Errors())
+		}
+		result := evaluator.Eval(prog, object.NewEnvironment())
+		if got := echoes(prog, result); got != tt.echo {
+			t.Errorf("echoes(%q) = %t, want %t", tt.input, got, tt.echo)
+		}
+	}
+}
@@ -0,0 +1,570 @@
+// Package analysis checks programs for type errors without running them.
+//
+// The checker only knows the types of literals, of the values computed from
+// them and of the variables, parameters and functions annotated with a type.
+// Everything else is dynamically typed and is left to the evaluator.
+package analysis
+
+import (
+	"fmt"
+	"interpreter/internal/ast"
+	"interpreter/internal/object"
+	"interpreter/internal/token"
+	"slices"
+)
+
+// Diagnostic is a problem found in a program by Check.
+type Diagnostic struct {
+	Token   token.Token
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return d.Token.Position() + ": " + d.Message
+}
+
+// unknown is the type of the expressions whose type is only known at runtime.
+const unknown object.ObjectType = ""
+
+var declaredTypes = map[token.TokenType]object.ObjectType{
+	token.TOKEN_INT:    object.INTEGER_OBJ,
+	token.TOKEN_FLOAT:  object.FLOAT_OBJ,
+	token.TOKEN_STRING: object.STRING_OBJ,
+	token.TOKEN_BOOL:   object.BOOLEAN_OBJ,
+	token.TOKEN_BYTE:   object.BYTE_OBJ,
+}
+
+// signature describes the parameters and result of a known function. Variadic
+// functions accept any number of arguments.
+type signature struct {
+	params     []object.ObjectType
+	names      []string
+	variadic   bool
+	returnType object.ObjectType
+	// defaults is the number of parameters at the end of params that have a
+	// default value.
+	defaults int
+	// rest is set for functions declared with a ...name parameter, which
+	// takes any number of arguments of type restType after params.
+	rest     bool
+	restType object.ObjectType
+	restName string
+	// builtin is set for the standard functions, which take no named
+	// arguments.
+	builtin bool
+}
+
+// builtins mirrors the standard functions of the evaluator.
+var builtins = map[string]*signature{
+	"print":    {builtin: true, variadic: true},
+	"panic":    {builtin: true, variadic: true},
+	"len":      {builtin: true, params: []object.ObjectType{unknown}, returnType: object.INTEGER_OBJ},
+	"read":     {builtin: true, params: []object.ObjectType{object.STRING_OBJ}, returnType: object.STRING_OBJ},
+	"write":    {builtin: true, params: []object.ObjectType{object.STRING_OBJ, unknown}},
+	"keys":     {builtin: true, params: []object.ObjectType{object.HASH_OBJ}, returnType: object.ARRAY_OBJ},
+	"values":   {builtin: true, params: []object.ObjectType{object.HASH_OBJ}, returnType: object.ARRAY_OBJ},
+	"has":      {builtin: true, params: []object.ObjectType{object.HASH_OBJ, unknown}, returnType: object.BOOLEAN_OBJ},
+	"delete":   {builtin: true, params: []object.ObjectType{object.HASH_OBJ, unknown}},
+	"byte":     {builtin: true, params: []object.ObjectType{unknown}, returnType: object.BYTE_OBJ},
+	"int":      {builtin: true, params: []object.ObjectType{unknown}, returnType: object.INTEGER_OBJ},
+	"string":   {builtin: true, params: []object.ObjectType{unknown}, returnType: object.STRING_OBJ},
+	"bytes":    {builtin: true, params: []object.ObjectType{unknown}, returnType: object.BYTES_OBJ},
+	"trace":    {builtin: true, returnType: object.ARRAY_OBJ},
+	"is_error": {builtin: true, params: []object.ObjectType{unknown}, returnType: object.BOOLEAN_OBJ},
+	"error":    {builtin: true, params: []object.ObjectType{object.STRING_OBJ}, returnType: object.ERROR_VALUE_OBJ},
+	"load":     {builtin: true, params: []object.ObjectType{object.STRING_OBJ}, returnType: object.NAMESPACE_OBJ},
+}
+
+// variable is what the checker knows about a name. typ is only set for
+// variables declared with a type, fn only for functions defined with a
+// function statement.
+type variable struct {
+	typ object.ObjectType
+	fn  *signature
+}
+
+type scope struct {
+	vars  map[string]variable
+	outer *scope
+}
+
+func (s *scope) lookup(name string) (variable, bool) {
+	for sc := s; sc != nil; sc = sc.outer {
+		if v, ok := sc.vars[name]; ok {
+			return v, true
+		}
+	}
+	return variable{}, false
+}
+
+type checker struct {
+	diagnostics []Diagnostic
+	scope       *scope
+	// returnTypes holds the declared return types of the functions enclosing
+	// the node being checked, innermost last.
+	returnTypes []object.ObjectType
+}
+
+// Check walks program and reports the type errors it can find before the
+// program runs: operators applied to operands they do not support, calls with
+// the wrong number of arguments and values that do not match the type of the
+// variable, parameter or function result they are used for.
+func Check(program *ast.Program) []Diagnostic {
+	c := &checker{}
+	c.pushScope()
+	for _, stmt := range program.Statements {
+		c.statement(stmt)
+	}
+	return c.diagnostics
+}
+
+func (c *checker) errorf(tok token.Token, format string, a ...any) {
+	c.diagnostics = append(c.diagnostics, Diagnostic{Token: tok, Message: fmt.Sprintf(format, a...)})
+}
+
+func (c *checker) pushScope() {
+	c.scope = &scope{vars: make(map[string]variable), outer: c.scope}
+}
+
+func (c *checker) popScope() {
+	c.scope = c.scope.outer
+}
+
+func (c *checker) declare(name string, v variable) {
+	c.scope.vars[name] = v
+}
+
+// assignable reports whether a value of type typ can be stored in a variable
+// declared with type target. Integers widen to floats.
+func assignable(typ, target object.ObjectType) bool {
+	return typ == unknown || target == unknown || typ == target ||
+		(typ == object.INTEGER_OBJ && target == object.FLOAT_OBJ)
+}
+
+func (c *checker) statement(node ast.Statement) {
+	switch node := node.(type) {
+	case *ast.VarStatement:
+		typ := c.expression(node.Value)
+		declared := declaredTypes[node.Token.Type]
+		if node.Value != nil && !assignable(typ, declared) {
+			c.errorf(node.Identifier.Token, "cannot assign %s to %s of type %s", typ, node.Identifier.Value, declared)
+		}
+		c.declare(node.Identifier.Value, variable{typ: declared})
+	case *ast.AssignStatement:
+		c.assignment(node)
+	case *ast.ExpressionStatement:
+		c.expression(node.Expression)
+	case *ast.ReturnStatement:
+		typ := c.expression(node.Value)
+		if len(c.returnTypes) == 0 {
+			return
+		}
+		if declared := c.returnTypes[len(c.returnTypes)-1]; !assignable(typ, declared) {
+			c.errorf(node.Token, "cannot return %s from function returning %s", typ, declared)
+		}
+	case *ast.BlockStatement:
+		c.block(node)
+	case *ast.TryStatement:
+		c.block(node.Body)
+		if node.Catch != nil {
+			c.pushScope()
+			c.declare(node.Param.Value, variable{})
+			c.block(node.Catch)
+			c.popScope()
+		}
+		if node.Finally != nil {
+			c.block(node.Finally)
+		}
+	case *ast.ThrowStatement:
+		c.expression(node.Value)
+	case *ast.ImportStatement:
+		c.declare(node.Name.Value, variable{})
+	case *ast.IfStatement:
+		c.expression(node)
+	case *ast.WhileStatement:
+		c.expression(node.Condition)
+		c.block(&node.Body)
+	case *ast.ForStatement:
+		c.pushScope()
+		if node.Init != nil {
+			c.statement(node.Init)
+		}
+		c.expression(node.Condition)
+		if node.Post != nil {
+			c.statement(node.Post)
+		}
+		c.block(node.Body)
+		c.popScope()
+	case *ast.ForInStatement:
+		switch typ := c.expression(node.Iterable); typ {
+		case unknown, object.ARRAY_OBJ, object.HASH_OBJ, object.STRING_OBJ, object.BYTES_OBJ:
+		default:
+			c.errorf(node.Token, "cannot iterate over %s", typ)
+		}
+		c.pushScope()
+		c.declare(node.Variable.Value, variable{})
+		c.block(node.Body)
+		c.popScope()
+	case *ast.FunctionStatement:
+		sig := functionSignature(node.ParameterList, node.ReturnType)
+		c.declare(node.Identifier.Value, variable{fn: sig})
+		c.function(node.ParameterList, sig, node.Body)
+	case *ast.ClassStatement:
+		c.declare(node.Identifier.Value, variable{})
+		for _, method := range node.Methods {
+			c.function(method.ParameterList, functionSignature(method.ParameterList, method.ReturnType), method.Body)
+		}
+	case *ast.StructStatement:
+		// the constructor is checked like a function returning a struct
+		sig := functionSignature(node.Fields, token.Token{})
+		sig.returnType = object.STRUCT_OBJ
+		c.declare(node.Identifier.Value, variable{fn: sig})
+		c.function(node.Fields, sig, &ast.BlockStatement{})
+	}
+}
+
+func (c *checker) block(node *ast.BlockStatement) {
+	c.pushScope()
+	for _, stmt := range node.Statements {
+		c.statement(stmt)
+	}
+	c.popScope()
+}
+
+func (c *checker) assignment(node *ast.AssignStatement) {
+	typ := c.expression(node.Value)
+	ident, ok := node.Target.(*ast.IdentifierExpression)
+	if !ok {
+		c.expression(node.Target)
+		return
+	}
+	v, _ := c.scope.lookup(ident.Value)
+	if node.Operator != "" {
+		typ = c.infix(node.Token, v.typ, typ, node.Operator)
+	}
+	if !assignable(typ, v.typ) {
+		c.errorf(ident.Token, "cannot assign %s to %s of type %s", typ, ident.Value, v.typ)
+	}
+}
+
+func functionSignature(params []ast.IdentifierExpression, returnType token.Token) *signature {
+	sig := &signature{returnType: declaredTypes[returnType.Type]}
+	for _, param := range params {
+		if param.Variadic {
+			sig.rest, sig.restType, sig.restName = true, declaredTypes[param.Type.Type], param.Value
+			break
+		}
+		sig.params = append(sig.params, declaredTypes[param.Type.Type])
+		if param.Default != nil {
+			sig.defaults++
+		}
+		sig.names = append(sig.names, param.Value)
+	}
+	return sig
+}
+
+func (c *checker) function(params []ast.IdentifierExpression, sig *signature, body *ast.BlockStatement) {
+	c.pushScope()
+	for i, param := range params {
+		if param.Variadic {
+			c.declare(param.Value, variable{})
+			break
+		}
+		if param.Default != nil {
+			if typ := c.expression(param.Default); !assignable(typ, sig.params[i]) {
+				c.errorf(param.Token, "cannot assign %s to %s of type %s", typ, param.Value, sig.params[i])
+			}
+		}
+		c.declare(param.Value, variable{typ: sig.params[i]})
+	}
+	c.returnTypes = append(c.returnTypes, sig.returnType)
+	c.block(body)
+	c.returnTypes = c.returnTypes[:len(c.returnTypes)-1]
+	c.popScope()
+}
+
+// expression checks node and returns its type, or unknown when the type can
+// not be known before running the program.
+func (c *checker) expression(node ast.Expression) object.ObjectType {
+	switch node := node.(type) {
+	case *ast.IntegerLiteral:
+		return object.INTEGER_OBJ
+	case *ast.FloatLiteral:
+		return object.FLOAT_OBJ
+	case *ast.StringLiteral:
+		return object.STRING_OBJ
+	case *ast.BoolLiteral:
+		return object.BOOLEAN_OBJ
+	case *ast.ByteLiteral:
+		return object.BYTE_OBJ
+	case *ast.ArrayLiteral:
+		for _, value := range node.Values {
+			c.expression(value)
+		}
+		return object.ARRAY_OBJ
+	case *ast.HashLiteral:
+		for i, key := range node.Keys {
+			c.expression(key)
+			c.expression(node.Values[i])
+		}
+		return object.HASH_OBJ
+	case *ast.FunctionLiteral:
+		c.function(node.ParameterList, functionSignature(node.ParameterList, node.ReturnType), node.Body)
+		return object.FUNCTION_OBJ
+	case *ast.IdentifierExpression:
+		v, _ := c.scope.lookup(node.Value)
+		if v.fn != nil {
+			return object.FUNCTION_OBJ
+		}
+		return v.typ
+	case *ast.PrefixExpression:
+		return c.prefix(node.Token, c.expression(node.Right), node.Operator)
+	case *ast.InfixExpression:
+		left := c.expression(node.Left)
+		right := c.expression(node.Right)
+		return c.infix(node.Token, left, right, node.Operator)
+	case *ast.UpdateExpression:
+		switch typ := c.expression(node.Target); typ {
+		case unknown, object.INTEGER_OBJ, object.FLOAT_OBJ, object.BYTE_OBJ:
+			return typ
+		default:
+			c.errorf(node.Token, "operator %s unsupported for %s", node.Operator, typ)
+		}
+	case *ast.IndexExpression:
+		left := c.expression(node.Left)
+		c.expression(node.Index)
+		switch left {
+		case object.STRING_OBJ, object.BYTES_OBJ:
+			return object.BYTE_OBJ
+		case unknown, object.ARRAY_OBJ, object.HASH_OBJ, object.ERROR_VALUE_OBJ, object.NAMESPACE_OBJ:
+		default:
+			c.errorf(node.Token, "index expression must be applied to ARRAY, HASH, STRING, BYTES, ERROR_VALUE or NAMESPACE object, got %s", left)
+		}
+	case *ast.CallExpression:
+		return c.call(node)
+	case *ast.MemberExpression:
+		switch typ := c.expression(node.Object); typ {
+		case object.INTEGER_OBJ, object.FLOAT_OBJ, object.BOOLEAN_OBJ, object.BYTE_OBJ:
+			c.errorf(node.Token, "%s has no member %s", typ, node.Member.Value)
+		}
+	case *ast.PropagateExpression:
+		return c.expression(node.Value)
+	case *ast.NamedArgument:
+		return c.expression(node.Value)
+	case *ast.SpreadExpression:
+		switch typ := c.expression(node.Value); typ {
+		case unknown, object.ARRAY_OBJ:
+		default:
+			c.errorf(node.Token, "cannot spread %s, expected ARRAY", typ)
+		}
+	case *ast.IfStatement:
+		c.expression(node.Condition)
+		c.block(node.Body)
+		if node.Alternative != nil {
+			c.block(node.Alternative)
+		}
+	}
+	return unknown
+}
+
+func (c *checker) call(node *ast.CallExpression) object.ObjectType {
+	args := []object.ObjectType{}
+	for _, arg := range node.Parameters {
+		args = append(args, c.expression(arg))
+	}
+	var sig *signature
+	name := node.FunctionIdentifer.String()
+	if ident, ok := node.FunctionIdentifer.(*ast.IdentifierExpression); ok {
+		v, declared := c.scope.lookup(ident.Value)
+		if declared {
+			sig = v.fn
+		} else {
+			sig = builtins[ident.Value]
+		}
+	}
+	if sig == nil {
+		switch typ := c.expression(node.FunctionIdentifer); typ {
+		case unknown, object.FUNCTION_OBJ:
+		default:
+			c.errorf(node.Token, "cannot call %s", typ)
+		}
+		return unknown
+	}
+	// spread arguments pass an unknown number of arguments
+	var positional []object.ObjectType
+	var named []*ast.NamedArgument
+	var namedTypes []object.ObjectType
+	spread := false
+	for i, arg := range node.Parameters {
+		switch arg := arg.(type) {
+		case *ast.SpreadExpression:
+			spread = true
+		case *ast.NamedArgument:
+			named = append(named, arg)
+			namedTypes = append(namedTypes, args[i])
+			continue
+		}
+		positional = append(positional, args[i])
+	}
+	if len(named) != 0 && sig.builtin {
+		c.errorf(node.Token, "builtin functions do not accept named arguments")
+		return sig.returnType
+	}
+	if sig.variadic || spread {
+		return sig.returnType
+	}
+	required := len(sig.params) - sig.defaults
+	if (len(positional) > len(sig.params) && !sig.rest) || (len(positional) < required && len(named) == 0) {
+		c.arityError(node.Token, name, sig, len(positional))
+		return sig.returnType
+	}
+	for i, arg := range positional {
+		if i >= len(sig.params) {
+			if !assignable(arg, sig.restType) {
+				c.errorf(node.Token, "cannot pass %s as parameter %s of type %s", arg, sig.restName, sig.restType)
+			}
+			continue
+		}
+		if assignable(arg, sig.params[i]) {
+			continue
+		}
+		if i < len(sig.names) {
+			c.errorf(node.Token, "cannot pass %s as parameter %s of type %s", arg, sig.names[i], sig.params[i])
+		} else {
+			c.errorf(node.Token, "cannot pass %s as argument %d of %s, expected %s", arg, i+1, name, sig.params[i])
+		}
+	}
+	passed := make([]bool, len(sig.params))
+	for i := range positional {
+		if i < len(passed) {
+			passed[i] = true
+		}
+	}
+	for j, arg := range named {
+		i := slices.Index(sig.names, arg.Name)
+		if i == -1 {
+			c.errorf(arg.Token, "function %s has no parameter named %s", name, arg.Name)
+			continue
+		}
+		if passed[i] {
+			c.errorf(arg.Token, "argument %s is passed more than once", arg.Name)
+			continue
+		}
+		passed[i] = true
+		if !assignable(namedTypes[j], sig.params[i]) {
+			c.errorf(arg.Token, "cannot pass %s as parameter %s of type %s", namedTypes[j], arg.Name, sig.params[i])
+		}
+	}
+	for i := 0; i < required; i++ {
+		if !passed[i] {
+			c.errorf(node.Token, "function %s is missing argument %s", name, sig.names[i])
+		}
+	}
+	return sig.returnType
+}
+
+// arityError reports a call of the function name with got positional
+// arguments, when it takes fewer or needs more of them.
+func (c *checker) arityError(tok token.Token, name string, sig *signature, got int) {
+	required := len(sig.params) - sig.defaults
+	want, bound := required, "at least "
+	if got > required {
+		want, bound = len(sig.params), "at most "
+	}
+	if sig.defaults == 0 && !sig.rest {
+		bound = ""
+	}
+	arguments := "arguments"
+	if want == 1 {
+		arguments = "argument"
+	}
+	c.errorf(tok, "function %s expects %s%d %s, got %d", name, bound, want, arguments, got)
+}
+
+func (c *checker) prefix(tok token.Token, right object.ObjectType, operator string) object.ObjectType {
+	if right == unknown {
+		if operator == "!" {
+			return object.BOOLEAN_OBJ
+		}
+		return unknown
+	}
+	switch operator {
+	case "!":
+		return object.BOOLEAN_OBJ
+	case "-":
+		if right == object.INTEGER_OBJ || right == object.FLOAT_OBJ {
+			return right
+		}
+	case "~":
+		if right == object.INTEGER_OBJ || right == object.BYTE_OBJ {
+			return right
+		}
+	}
+	c.errorf(tok, "operator %s unsupported for %s", operator, right)
+	return unknown
+}
+
+func isNumeric(typ object.ObjectType) bool {
+	return typ == object.INTEGER_OBJ || typ == object.FLOAT_OBJ || typ == object.BYTE_OBJ
+}
+
+// infix returns the type of applying operator to operands of type left and
+// right, following the rules of the evaluator.
+func (c *checker) infix(tok token.Token, left, right object.ObjectType, operator string) object.ObjectType {
+	switch operator {
+	case "==", "!=":
+		return object.BOOLEAN_OBJ
+	}
+	if left == unknown || right == unknown {
+		switch operator {
+		case "<", ">", "<=", ">=", "and", "or":
+			return object.BOOLEAN_OBJ
+		}
+		return unknown
+	}
+	switch {
+	case left == object.BOOLEAN_OBJ && right == object.BOOLEAN_OBJ:
+		if operator == "and" || operator == "or" {
+			return object.BOOLEAN_OBJ
+		}
+	case left == object.STRING_OBJ && right == object.STRING_OBJ:
+		switch operator {
+		case "+":
+			return object.STRING_OBJ
+		case "<", ">", "<=", ">=":
+			return object.BOOLEAN_OBJ
+		}
+	case isNumeric(left) && isNumeric(right):
+		if typ, ok := numericInfix(left, right, operator); ok {
+			return typ
+		}
+	}
+	c.errorf(tok, "operator %s unsupported for %s and %s", operator, left, right)
+	return unknown
+}
+
+func numericInfix(left, right object.ObjectType, operator string) (object.ObjectType, bool) {
+	// bytes keep their type with each other and widen otherwise
+	result := object.ObjectType(object.BYTE_OBJ)
+	if left != right || left != object.BYTE_OBJ {
+		result = object.INTEGER_OBJ
+		if left == object.FLOAT_OBJ || right == object.FLOAT_OBJ {
+			result = object.FLOAT_OBJ
+		}
+	}
+	switch operator {
+	case "<", ">", "<=", ">=":
+		return object.BOOLEAN_OBJ, true
+	case "+", "-", "*", "/", "%":
+		return result, true
+	case "**":
+		if result == object.INTEGER_OBJ {
+			// negative exponents produce floats
+			return unknown, true
+		}
+		return result, true
+	case "&", "|", "^", "<<", ">>":
+		return result, result != object.FLOAT_OBJ
+	}
+	return unknown, false
+}
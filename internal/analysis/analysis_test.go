@@ -0,0 +1,128 @@
+package analysis_test
+
+import (
+	"interpreter/internal/analysis"
+	"interpreter/internal/lexer"
+	"interpreter/internal/parser"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	testCases := []struct {
+		input       string
+		diagnostics []string
+	}{
+		{
+			input:       `var x = 1 + 2.5; int y = len("abc"); float f = y * 2; string s = "a" + "b";`,
+			diagnostics: []string{},
+		},
+		{
+			input:       `var x = "a" - 1;`,
+			diagnostics: []string{"1:13: operator - unsupported for STRING and INTEGER"},
+		},
+		{
+			input:       `-"a"; !"a"; ~1.5;`,
+			diagnostics: []string{"1:1: operator - unsupported for STRING", "1:13: operator ~ unsupported for FLOAT"},
+		},
+		{
+			input:       `int n = 0; try { n = 1; } catch (e) { n = e["line"]; int m = e; } finally { n = "x"; } throw -"a";`,
+			diagnostics: []string{"1:77: cannot assign STRING to n of type INTEGER", "1:94: operator - unsupported for STRING"},
+		},
+		{
+			input:       `var e = error("x"); e["message"]; string d = read("f")?; int n = is_error(d);`,
+			diagnostics: []string{"1:62: cannot assign BOOLEAN to n of type INTEGER"},
+		},
+		{
+			input:       `fun f(int a, int ...rest) { return rest; } f(1); f(1, 2, 3); f(); f(1, "x"); f(...[1]); f(...1);`,
+			diagnostics: []string{"1:63: function f expects at least 1 argument, got 0", "1:68: cannot pass STRING as parameter rest of type INTEGER", "1:91: cannot spread INTEGER, expected ARRAY"},
+		},
+		{
+			input: `fun f(a, int b = 1, c = 2) { } f(1); f(b = 2, a = 1); f(); f(1, 2, 3, 4); f(c = 1); f(1, d = 2); f(1, a = 2); f(1, b = "x"); len(s = 1);`,
+			diagnostics: []string{
+				"1:56: function f expects at least 1 argument, got 0",
+				"1:61: function f expects at most 3 arguments, got 4",
+				"1:76: function f is missing argument a",
+				"1:90: function f has no parameter named d",
+				"1:103: argument a is passed more than once",
+				"1:116: cannot pass STRING as parameter b of type INTEGER",
+				"1:129: builtin functions do not accept named arguments",
+			},
+		},
+		{
+			input:       `fun f() { } f(a = 1); struct E { } E(x = 1); print(s = 1);`,
+			diagnostics: []string{"1:15: function f has no parameter named a", "1:38: function E has no parameter named x", "1:51: builtin functions do not accept named arguments"},
+		},
+		{
+			input:       `int x = 1; x.y; true.z; "a".upper(); load("math").pi;`,
+			diagnostics: []string{"1:13: INTEGER has no member y", "1:21: BOOLEAN has no member z"},
+		},
+		{
+			input: `struct P { int x, y = 1 } var p = P(1); P(); P(x = 1, z = 2); int n = P(1, 2); struct Q { int x = "a" }`,
+			diagnostics: []string{
+				"1:42: function P expects at least 1 argument, got 0",
+				"1:55: function P has no parameter named z",
+				"1:67: cannot assign STRUCT to n of type INTEGER",
+				"1:95: cannot assign STRING to x of type INTEGER",
+			},
+		},
+		{
+			input:       `class A { fun f(self, int n) int { return "x"; } } class B(A) { fun g(self) { return super.f(1); } } B().g();`,
+			diagnostics: []string{"1:36: cannot return STRING from function returning INTEGER"},
+		},
+		{
+			input:       `fun f(int a = "x") { }`,
+			diagnostics: []string{"1:11: cannot assign STRING to a of type INTEGER"},
+		},
+		{
+			input:       `int x = 1.5; var y = x; int z = y;`,
+			diagnostics: []string{"1:5: cannot assign FLOAT to x of type INTEGER"},
+		},
+		{
+			input:       `string s; s = 1; s += "ok"; s++;`,
+			diagnostics: []string{"1:11: cannot assign INTEGER to s of type STRING", "1:30: operator ++ unsupported for STRING"},
+		},
+		{
+			input:       `int x = 1; if true { var x = "shadow"; x = "ok"; } x = 2.0;`,
+			diagnostics: []string{"1:52: cannot assign FLOAT to x of type INTEGER"},
+		},
+		{
+			input: `fun f(int a, b) int { return a + b; } f(1); f("1", 2); f(1, "2");`,
+			diagnostics: []string{
+				"1:40: function f expects 2 arguments, got 1",
+				"1:46: cannot pass STRING as parameter a of type INTEGER",
+			},
+		},
+		{
+			input:       `fun f() int { return "s"; } var g = fun() string { return 1; };`,
+			diagnostics: []string{"1:15: cannot return STRING from function returning INTEGER", "1:52: cannot return INTEGER from function returning STRING"},
+		},
+		{
+			input:       `len(1, 2); keys([1]); print(1, 2, 3);`,
+			diagnostics: []string{"1:4: function len expects 1 argument, got 2", "1:16: cannot pass ARRAY as argument 1 of keys, expected HASH"},
+		},
+		{
+			input:       `int n = 1; n(); "abc"[0] + 1b; 5[0]; for c in 5 { }`,
+			diagnostics: []string{"1:13: cannot call INTEGER", "1:33: index expression must be applied to ARRAY, HASH, STRING, BYTES, ERROR_VALUE or NAMESPACE object, got INTEGER", "1:38: cannot iterate over INTEGER"},
+		},
+		{
+			input:       `fun f(x) { return x - 1; } f("a"); var a = [1]; a[0] - "b";`,
+			diagnostics: []string{},
+		},
+	}
+	for i, tC := range testCases {
+		p := parser.New(lexer.New(tC.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("tests[%d]: parse errors found: %s", i, p.Errors())
+		}
+		diagnostics := analysis.Check(program)
+		if len(diagnostics) != len(tC.diagnostics) {
+			t.Fatalf("tests[%d]: expected %d diagnostics, got %v", i, len(tC.diagnostics), diagnostics)
+		}
+		for j, d := range diagnostics {
+			if d.String() != tC.diagnostics[j] {
+				t.Errorf("tests[%d]: expected diagnostic %q, got %q", i, tC.diagnostics[j], d.String())
+			}
+		}
+	}
+}
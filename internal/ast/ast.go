@@ -2,12 +2,17 @@ package ast
 
 import (
 	"bytes"
+	"fmt"
 	"interpreter/internal/token"
+	"strconv"
+	"strings"
 )
 
 type Node interface {
 	TokenLiteral() string
 	String() string
+	// Pos returns the token that errors about the node are reported at.
+	Pos() token.Token
 }
 
 type Statement interface {
@@ -31,6 +36,13 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+func (p *Program) Pos() token.Token {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Token{}
+}
+
 func (p *Program) String() string {
 	var ret bytes.Buffer
 	for _, s := range p.Statements {
@@ -47,6 +59,7 @@ type InfixExpression struct {
 }
 
 func (ie *InfixExpression) expressionNode()      {}
+func (ie *InfixExpression) Pos() token.Token     { return ie.Token }
 func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Value }
 func (ie *InfixExpression) String() string {
 	var ret bytes.Buffer
@@ -65,6 +78,7 @@ type PrefixExpression struct {
 }
 
 func (pe *PrefixExpression) expressionNode()      {}
+func (pe *PrefixExpression) Pos() token.Token     { return pe.Token }
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Value }
 func (pe *PrefixExpression) String() string {
 	var ret bytes.Buffer
@@ -75,13 +89,46 @@ func (pe *PrefixExpression) String() string {
 	return ret.String()
 }
 
+// UpdateExpression is an increment or decrement of a variable or an index
+// expression, written either before (++x) or after (x++) its target.
+type UpdateExpression struct {
+	Token    token.Token // token.TOKEN_INCREMENT or token.TOKEN_DECREMENT token
+	Operator string
+	Target   Expression
+	Postfix  bool
+}
+
+func (ue *UpdateExpression) expressionNode()      {}
+func (ue *UpdateExpression) Pos() token.Token     { return ue.Token }
+func (ue *UpdateExpression) TokenLiteral() string { return ue.Token.Value }
+func (ue *UpdateExpression) String() string {
+	var ret bytes.Buffer
+	ret.WriteString("(")
+	if ue.Postfix {
+		ret.WriteString(ue.Target.String())
+		ret.WriteString(ue.Operator)
+	} else {
+		ret.WriteString(ue.Operator)
+		ret.WriteString(ue.Target.String())
+	}
+	ret.WriteString(")")
+	return ret.String()
+}
+
 type IdentifierExpression struct {
 	Token token.Token
 	Type  token.Token
 	Value string
+	// Variadic marks the last parameter of a function declared as ...name,
+	// which collects the remaining arguments into an array.
+	Variadic bool
+	// Default is the value of a parameter declared as name = value when the
+	// call does not pass it.
+	Default Expression
 }
 
 func (ie *IdentifierExpression) expressionNode()      {}
+func (ie *IdentifierExpression) Pos() token.Token     { return ie.Token }
 func (ie *IdentifierExpression) TokenLiteral() string { return ie.Token.Value }
 func (ie *IdentifierExpression) String() string       { return ie.Value }
 
@@ -91,6 +138,7 @@ type ExpressionStatement struct {
 }
 
 func (es *ExpressionStatement) statementNode()       {}
+func (es *ExpressionStatement) Pos() token.Token     { return es.Token }
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Value }
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
@@ -103,10 +151,12 @@ type FunctionStatement struct {
 	Token         token.Token // token.TOKEN_FUNCTION
 	Identifier    token.Token
 	ParameterList []IdentifierExpression
+	ReturnType    token.Token // type token, zero when the function is untyped
 	Body          *BlockStatement
 }
 
-func (fs *FunctionStatement) statementNode() {}
+func (fs *FunctionStatement) statementNode()   {}
+func (fs *FunctionStatement) Pos() token.Token { return fs.Token }
 func (fs *FunctionStatement) TokenLiteral() string {
 	return fs.Identifier.Value
 }
@@ -115,23 +165,119 @@ func (fs *FunctionStatement) String() string {
 	buf.WriteString("func ")
 	buf.WriteString(fs.Identifier.Value)
 	buf.WriteString("(")
-	for i, p := range fs.ParameterList {
-		buf.WriteString(p.String())
-		if len(fs.ParameterList) > i+1 {
-			buf.WriteString(", ")
-		}
-	}
+	buf.WriteString(parameterListString(fs.ParameterList))
 	buf.WriteString(") ")
+	if fs.ReturnType.Type != "" {
+		buf.WriteString(string(fs.ReturnType.Type) + " ")
+	}
 	buf.WriteString(fs.Body.String())
 	return buf.String()
 }
 
+type FunctionLiteral struct {
+	Token         token.Token // token.TOKEN_FUN token
+	ParameterList []IdentifierExpression
+	ReturnType    token.Token // type token, zero when the function is untyped
+	Body          *BlockStatement
+}
+
+func (fl *FunctionLiteral) expressionNode()      {}
+func (fl *FunctionLiteral) Pos() token.Token     { return fl.Token }
+func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Value }
+func (fl *FunctionLiteral) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("fun(")
+	buf.WriteString(parameterListString(fl.ParameterList))
+	buf.WriteString(") ")
+	if fl.ReturnType.Type != "" {
+		buf.WriteString(string(fl.ReturnType.Type) + " ")
+	}
+	buf.WriteString("{")
+	buf.WriteString(fl.Body.String())
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// StructStatement declares a struct type with named fields, as in
+// struct Point { x, y }. The fields are declared like parameters, with
+// optional types and defaults.
+type StructStatement struct {
+	Token      token.Token // token.TOKEN_STRUCT token
+	Identifier token.Token
+	Fields     []IdentifierExpression
+}
+
+func (ss *StructStatement) statementNode()       {}
+func (ss *StructStatement) Pos() token.Token     { return ss.Token }
+func (ss *StructStatement) TokenLiteral() string { return ss.Token.Value }
+func (ss *StructStatement) String() string {
+	return "struct " + ss.Identifier.Value + " { " + parameterListString(ss.Fields) + " }"
+}
+
+// ClassStatement declares a class with methods, and optionally the class it
+// inherits from, as in class Stack(Collection) { fun push(self, v) { ... } }.
+type ClassStatement struct {
+	Token      token.Token // token.TOKEN_CLASS token
+	Identifier token.Token
+	Parent     *IdentifierExpression // nil when the class does not inherit
+	Methods    []*FunctionStatement
+}
+
+func (cs *ClassStatement) statementNode()       {}
+func (cs *ClassStatement) Pos() token.Token     { return cs.Token }
+func (cs *ClassStatement) TokenLiteral() string { return cs.Token.Value }
+func (cs *ClassStatement) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("class " + cs.Identifier.Value)
+	if cs.Parent != nil {
+		buf.WriteString("(" + cs.Parent.Value + ")")
+	}
+	buf.WriteString(" { ")
+	for _, method := range cs.Methods {
+		buf.WriteString(method.String())
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// SuperExpression refers to the methods of the parent class inside a method,
+// as in super.push(v).
+type SuperExpression struct {
+	Token token.Token // token.TOKEN_SUPER token
+}
+
+func (se *SuperExpression) expressionNode()      {}
+func (se *SuperExpression) Pos() token.Token     { return se.Token }
+func (se *SuperExpression) TokenLiteral() string { return se.Token.Value }
+func (se *SuperExpression) String() string       { return "super" }
+
+// parameterListString prints parameters with their optional types, defaults
+// and the ... of a variadic parameter, as in "int a, b = 1, ...rest".
+func parameterListString(params []IdentifierExpression) string {
+	list := []string{}
+	for _, p := range params {
+		param := p.String()
+		if p.Variadic {
+			param = "..." + param
+		}
+		if p.Type.Type != "" {
+			param = string(p.Type.Type) + " " + param
+		}
+		if p.Default != nil {
+			param += " = " + p.Default.String()
+		}
+		list = append(list, param)
+	}
+	return strings.Join(list, ", ")
+}
+
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
 }
 
 func (il *IntegerLiteral) expressionNode()      {}
+func (il *IntegerLiteral) Pos() token.Token     { return il.Token }
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Value }
 func (il *IntegerLiteral) String() string       { return il.Token.Value }
 
@@ -141,6 +287,7 @@ type FloatLiteral struct {
 }
 
 func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) Pos() token.Token     { return fl.Token }
 func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Value }
 func (fl *FloatLiteral) String() string       { return fl.Token.Value }
 
@@ -149,7 +296,8 @@ type BoolLiteral struct {
 	Value bool
 }
 
-func (bl *BoolLiteral) expressionNode() {}
+func (bl *BoolLiteral) expressionNode()  {}
+func (bl *BoolLiteral) Pos() token.Token { return bl.Token }
 func (bl *BoolLiteral) TokenLiteral() string {
 	// TODO: set value of Token.Value while scanning tokens and remove code below
 	if bl.Value {
@@ -173,10 +321,31 @@ type StringLiteral struct {
 }
 
 func (sl *StringLiteral) expressionNode()      {}
+func (sl *StringLiteral) Pos() token.Token     { return sl.Token }
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Value }
 func (sl *StringLiteral) String() string       { return sl.Token.Value }
 
-// TODO: add byte and float literal nodes
+// ByteLiteral is either a character literal ('a', '\x61') or a decimal number
+// with the byte suffix (97b).
+type ByteLiteral struct {
+	Token token.Token
+	Value byte
+}
+
+func (bl *ByteLiteral) expressionNode()      {}
+func (bl *ByteLiteral) Pos() token.Token     { return bl.Token }
+func (bl *ByteLiteral) TokenLiteral() string { return bl.Token.Value }
+func (bl *ByteLiteral) String() string {
+	if bl.Token.Type == token.CHAR {
+		// QuoteRuneToASCII would print the bytes from 0x80 up as \u escapes
+		if bl.Value >= 0x80 {
+			return fmt.Sprintf(`'\x%02x'`, bl.Value)
+		}
+		return strconv.QuoteRuneToASCII(rune(bl.Value))
+	}
+	return bl.Token.Value
+}
+
 type VarStatement struct {
 	Token      token.Token // type token
 	Identifier *IdentifierExpression
@@ -184,6 +353,7 @@ type VarStatement struct {
 }
 
 func (vs *VarStatement) statementNode()       {}
+func (vs *VarStatement) Pos() token.Token     { return vs.Token }
 func (vs *VarStatement) TokenLiteral() string { return vs.Token.Value }
 func (vs *VarStatement) String() string {
 	var out bytes.Buffer
@@ -199,12 +369,34 @@ func (vs *VarStatement) String() string {
 	return out.String()
 }
 
+type AssignStatement struct {
+	Token  token.Token // token.TOKEN_ASSIGN or compound assignment token
+	Target Expression
+	// Operator is the infix operator applied by a compound assignment such
+	// as +=, empty for plain assignment.
+	Operator string
+	Value    Expression
+}
+
+func (as *AssignStatement) statementNode()       {}
+func (as *AssignStatement) Pos() token.Token     { return as.Token }
+func (as *AssignStatement) TokenLiteral() string { return as.Token.Value }
+func (as *AssignStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(as.Target.String())
+	out.WriteString(" " + as.Operator + "= ")
+	out.WriteString(as.Value.String())
+	out.WriteString(";")
+	return out.String()
+}
+
 type BlockStatement struct {
 	Token      token.Token // token.TOKEN_LCURLY token
 	Statements []Statement
 }
 
 func (bs *BlockStatement) statementNode()       {}
+func (bs *BlockStatement) Pos() token.Token     { return bs.Token }
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Value }
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
@@ -217,14 +409,17 @@ func (bs *BlockStatement) String() string {
 
 type WhileStatement struct {
 	Token     token.Token // token.TOKEN_WHILE token
+	Label     *IdentifierExpression
 	Condition Expression
 	Body      BlockStatement
 }
 
 func (ws *WhileStatement) statementNode()       {}
+func (ws *WhileStatement) Pos() token.Token     { return ws.Token }
 func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Value }
 func (ws *WhileStatement) String() string {
 	var out bytes.Buffer
+	out.WriteString(labelString(ws.Label))
 	out.WriteString(ws.Token.Value)
 	out.WriteString("while (")
 	out.WriteString(ws.Condition.String())
@@ -235,40 +430,232 @@ func (ws *WhileStatement) String() string {
 	return out.String()
 }
 
+type ForStatement struct {
+	Token     token.Token // token.TOKEN_FOR token
+	Label     *IdentifierExpression
+	Init      Statement
+	Condition Expression
+	Post      Statement
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) Pos() token.Token     { return fs.Token }
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Value }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(labelString(fs.Label))
+	out.WriteString("for ")
+	if fs.Init != nil {
+		out.WriteString(fs.Init.String())
+	} else {
+		out.WriteString(";")
+	}
+	out.WriteString(" ")
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+	out.WriteString(";")
+	if fs.Post != nil {
+		out.WriteString(" ")
+		out.WriteString(strings.TrimSuffix(fs.Post.String(), ";"))
+	}
+	out.WriteString(" {")
+	out.WriteString(fs.Body.String())
+	out.WriteString("}")
+	return out.String()
+}
+
+type ForInStatement struct {
+	Token    token.Token // token.TOKEN_FOR token
+	Label    *IdentifierExpression
+	Variable *IdentifierExpression
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForInStatement) statementNode()       {}
+func (fs *ForInStatement) Pos() token.Token     { return fs.Token }
+func (fs *ForInStatement) TokenLiteral() string { return fs.Token.Value }
+func (fs *ForInStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(labelString(fs.Label))
+	out.WriteString("for ")
+	out.WriteString(fs.Variable.String())
+	out.WriteString(" in ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(" {")
+	out.WriteString(fs.Body.String())
+	out.WriteString("}")
+	return out.String()
+}
+
+type BreakStatement struct {
+	Token token.Token // token.TOKEN_BREAK token
+	Label *IdentifierExpression
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) Pos() token.Token     { return bs.Token }
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Value }
+func (bs *BreakStatement) String() string {
+	if bs.Label != nil {
+		return "break " + bs.Label.String() + ";"
+	}
+	return "break;"
+}
+
+type ContinueStatement struct {
+	Token token.Token // token.TOKEN_CONTINUE token
+	Label *IdentifierExpression
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) Pos() token.Token     { return cs.Token }
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Value }
+func (cs *ContinueStatement) String() string {
+	if cs.Label != nil {
+		return "continue " + cs.Label.String() + ";"
+	}
+	return "continue;"
+}
+
+func labelString(label *IdentifierExpression) string {
+	if label == nil {
+		return ""
+	}
+	return label.String() + ": "
+}
+
+// IfStatement is also used as an expression, evaluating to the value of the
+// branch that was taken. An `else if` is stored as an Alternative holding a
+// single nested IfStatement.
 type IfStatement struct {
-	Token       token.Token // token.TOKEN_WHILE token
+	Token       token.Token // token.TOKEN_IF token
 	Condition   Expression
 	Body        *BlockStatement
 	Alternative *BlockStatement
 }
 
 func (is *IfStatement) statementNode()       {}
+func (is *IfStatement) expressionNode()      {}
+func (is *IfStatement) Pos() token.Token     { return is.Token }
 func (is *IfStatement) TokenLiteral() string { return is.Token.Value }
 func (is *IfStatement) String() string {
 	var out bytes.Buffer
-	out.WriteString(is.Token.Value)
-	out.WriteString("(")
+	out.WriteString("if ")
 	out.WriteString(is.Condition.String())
-	out.WriteString(")")
+	out.WriteString(" {")
 	out.WriteString(is.Body.String())
+	out.WriteString("}")
 	if is.Alternative != nil {
 		out.WriteString(" else ")
-		out.WriteString(is.Alternative.String())
+		if elseIf := is.ElseIf(); elseIf != nil {
+			out.WriteString(elseIf.String())
+		} else {
+			out.WriteString("{")
+			out.WriteString(is.Alternative.String())
+			out.WriteString("}")
+		}
 	}
 	return out.String()
 }
 
+// ElseIf returns the nested if statement of an `else if` branch, or nil when
+// the alternative is a plain else block.
+func (is *IfStatement) ElseIf() *IfStatement {
+	if is.Alternative == nil || is.Alternative.Token.Type != token.TOKEN_IF || len(is.Alternative.Statements) != 1 {
+		return nil
+	}
+	elseIf, _ := is.Alternative.Statements[0].(*IfStatement)
+	return elseIf
+}
+
+// PropagateExpression is the postfix ? operator, which returns the value of
+// its operand from the enclosing function if it is an error.
+type PropagateExpression struct {
+	Token token.Token // token.TOKEN_QUESTION token
+	Value Expression
+}
+
+func (pe *PropagateExpression) expressionNode()      {}
+func (pe *PropagateExpression) Pos() token.Token     { return pe.Token }
+func (pe *PropagateExpression) TokenLiteral() string { return pe.Token.Value }
+func (pe *PropagateExpression) String() string {
+	return "(" + pe.Value.String() + "?)"
+}
+
+type TryStatement struct {
+	Token   token.Token // token.TOKEN_TRY token
+	Body    *BlockStatement
+	Param   *IdentifierExpression
+	Catch   *BlockStatement
+	Finally *BlockStatement
+}
+
+func (ts *TryStatement) statementNode()       {}
+func (ts *TryStatement) Pos() token.Token     { return ts.Token }
+func (ts *TryStatement) TokenLiteral() string { return ts.Token.Value }
+func (ts *TryStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("try {")
+	out.WriteString(ts.Body.String())
+	out.WriteString("}")
+	if ts.Catch != nil {
+		out.WriteString(" catch (")
+		out.WriteString(ts.Param.String())
+		out.WriteString(") {")
+		out.WriteString(ts.Catch.String())
+		out.WriteString("}")
+	}
+	if ts.Finally != nil {
+		out.WriteString(" finally {")
+		out.WriteString(ts.Finally.String())
+		out.WriteString("}")
+	}
+	return out.String()
+}
+
+type ThrowStatement struct {
+	Token token.Token // token.TOKEN_THROW token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) Pos() token.Token     { return ts.Token }
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Value }
+func (ts *ThrowStatement) String() string {
+	return "throw " + ts.Value.String() + ";"
+}
+
+// ImportStatement binds the top-level names of another source file to Name,
+// as in import "util.mst" as util;
+type ImportStatement struct {
+	Token token.Token // token.TOKEN_IMPORT token
+	Path  *StringLiteral
+	Name  *IdentifierExpression
+}
+
+func (is *ImportStatement) statementNode()       {}
+func (is *ImportStatement) Pos() token.Token     { return is.Token }
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Value }
+func (is *ImportStatement) String() string {
+	return "import \"" + is.Path.Value + "\" as " + is.Name.Value + ";"
+}
+
 type ReturnStatement struct {
 	Token token.Token // token.TOKEN_RETURN token
 	Value Expression
 }
 
 func (rs *ReturnStatement) statementNode()       {}
+func (rs *ReturnStatement) Pos() token.Token     { return rs.Token }
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Value }
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
-	out.WriteString(rs.Token.Value)
+	out.WriteString("return ")
 	out.WriteString(rs.Value.String())
+	out.WriteString(";")
 	return out.String()
 }
 
@@ -278,6 +665,7 @@ type ArrayLiteral struct {
 }
 
 func (al *ArrayLiteral) expressionNode()      {}
+func (al *ArrayLiteral) Pos() token.Token     { return al.Token }
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Value }
 func (al *ArrayLiteral) String() string {
 	var buf bytes.Buffer
@@ -292,6 +680,30 @@ func (al *ArrayLiteral) String() string {
 	return buf.String()
 }
 
+type HashLiteral struct {
+	Token  token.Token // token.TOKEN_LCURLY token
+	Keys   []Expression
+	Values []Expression
+}
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) Pos() token.Token     { return hl.Token }
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Value }
+func (hl *HashLiteral) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, key := range hl.Keys {
+		buf.WriteString(key.String())
+		buf.WriteString(": ")
+		buf.WriteString(hl.Values[i].String())
+		if len(hl.Keys) > i+1 {
+			buf.WriteString(", ")
+		}
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
 type IndexExpression struct {
 	Token token.Token
 	Left  Expression
@@ -299,6 +711,7 @@ type IndexExpression struct {
 }
 
 func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) Pos() token.Token     { return ie.Token }
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Value }
 func (ie *IndexExpression) String() string {
 	var buf bytes.Buffer
@@ -311,6 +724,46 @@ func (ie *IndexExpression) String() string {
 	return buf.String()
 }
 
+// MemberExpression looks up a member of an object, as in util.add or
+// "abc".upper.
+type MemberExpression struct {
+	Token  token.Token // token.TOKEN_DOT token
+	Object Expression
+	Member *IdentifierExpression
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) Pos() token.Token     { return me.Token }
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Value }
+func (me *MemberExpression) String() string {
+	return "(" + me.Object.String() + "." + me.Member.Value + ")"
+}
+
+// SpreadExpression passes the elements of an array as separate arguments of
+// a call, as in f(...args).
+type SpreadExpression struct {
+	Token token.Token // token.TOKEN_ELLIPSIS token
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode()      {}
+func (se *SpreadExpression) Pos() token.Token     { return se.Token }
+func (se *SpreadExpression) TokenLiteral() string { return se.Token.Value }
+func (se *SpreadExpression) String() string       { return "..." + se.Value.String() }
+
+// NamedArgument passes an argument to the parameter called Name, as in
+// connect(host, verbose = true).
+type NamedArgument struct {
+	Token token.Token // the token of the parameter name
+	Name  string
+	Value Expression
+}
+
+func (na *NamedArgument) expressionNode()      {}
+func (na *NamedArgument) Pos() token.Token     { return na.Token }
+func (na *NamedArgument) TokenLiteral() string { return na.Token.Value }
+func (na *NamedArgument) String() string       { return na.Name + " = " + na.Value.String() }
+
 type CallExpression struct {
 	Token             token.Token // ( token
 	FunctionIdentifer Expression
@@ -318,6 +771,7 @@ type CallExpression struct {
 }
 
 func (ce *CallExpression) expressionNode()      {}
+func (ce *CallExpression) Pos() token.Token     { return ce.Token }
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Value }
 func (ce *CallExpression) String() string {
 	var buf bytes.Buffer
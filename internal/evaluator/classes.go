@@ -0,0 +1,58 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/internal/ast"
+	"interpreter/internal/object"
+	"interpreter/internal/token"
+)
+
+func evalClassStatement(node *ast.ClassStatement, env *object.Environment) object.Object {
+	class := &object.Class{Name: node.Identifier.Value, Methods: map[string]*object.Function{}}
+	if node.Parent != nil {
+		parent := Eval(node.Parent, env)
+		if isError(parent) {
+			return parent
+		}
+		parentClass, ok := parent.(*object.Class)
+		if !ok {
+			return &object.Error{Error: fmt.Sprintf("class %s can not inherit from %s", class.Name, typeOf(parent)), Token: node.Parent.Token}
+		}
+		class.Parent = parentClass
+	}
+	for _, method := range node.Methods {
+		class.Methods[method.Identifier.Value] = &object.Function{
+			Name:       class.Name + "." + method.Identifier.Value,
+			Params:     method.ParameterList,
+			ReturnType: method.ReturnType,
+			Body:       method.Body,
+			Env:        env,
+		}
+	}
+	env.Set(class.Name, class)
+	return nil
+}
+
+// instantiate creates an instance of class and initializes it by calling its
+// init method with the arguments.
+func instantiate(class *object.Class, params []object.Object, named []namedArgument, env *object.Environment, call token.Token) object.Object {
+	instance := &object.Instance{Class: class, Fields: object.NewEnvironment()}
+	init, definedBy := class.Method("init")
+	if init == nil {
+		if len(params) != 0 || len(named) != 0 {
+			return &object.Error{Error: fmt.Sprintf("class %s has no init method and takes no arguments, got %d", class.Name, len(params)+len(named))}
+		}
+		return instance
+	}
+	if ret := evalFunction(object.Bind(init, definedBy, instance), params, named, env, call); isError(ret) {
+		return ret
+	}
+	return instance
+}
+
+func evalSuperExpression(env *object.Environment) object.Object {
+	if super, ok := env.Get("super"); ok {
+		return super
+	}
+	return &object.Error{Error: "super can only be used in the methods of a class that inherits"}
+}
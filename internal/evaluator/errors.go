@@ -0,0 +1,74 @@
+package evaluator
+
+import (
+	"interpreter/internal/ast"
+	"interpreter/internal/object"
+)
+
+// evalTryStatement runs the try block, handing an error it raises to the
+// catch block. The finally block always runs last; if it raises an error or
+// leaves the function or loop itself, that takes the place of the result of
+// the other blocks.
+func evalTryStatement(node *ast.TryStatement, env *object.Environment) object.Object {
+	ret := Eval(node.Body, env)
+	// the ? operator returns from the function rather than raising an error
+	if err, ok := ret.(*object.Error); ok && err.Propagating == nil && node.Catch != nil {
+		catchEnv := object.NewEnclosedEnvironment(env)
+		catchEnv.Set(node.Param.Value, &object.ErrorValue{Err: err})
+		ret = Eval(node.Catch, catchEnv)
+	}
+	if node.Finally != nil {
+		finally := Eval(node.Finally, env)
+		if finally != nil {
+			switch finally.Type() {
+			case object.ERROR_OBJ, object.RETURN_VALUE_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+				return finally
+			}
+		}
+	}
+	return ret
+}
+
+// evalThrowStatement raises the thrown value as an error. Throwing a caught
+// error raises it again as it was.
+func evalThrowStatement(node *ast.ThrowStatement, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if val == nil {
+		val = NULL
+	}
+	switch val := val.(type) {
+	case *object.Error:
+		return val
+	case *object.ErrorValue:
+		return val.Err
+	}
+	return &object.Error{Error: val.Inspect(), Kind: object.USER_ERROR, Value: val}
+}
+
+// evalPropagateExpression returns the value of its operand, unless it is an
+// error value. Then it unwinds the enclosing function, which returns the error
+// value, like an error would but without being caught by try.
+func evalPropagateExpression(node *ast.PropagateExpression, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	ev, ok := val.(*object.ErrorValue)
+	if !ok {
+		return val
+	}
+	return &object.Error{Error: ev.Err.Error, Token: node.Token, Propagating: ev}
+}
+
+// errorField returns the field of a caught error named by index.
+func errorField(err *object.Error, index object.Object) object.Object {
+	name, ok := index.(*object.String)
+	if !ok {
+		return &object.Error{Error: "error field must be a STRING, got " + string(typeOf(index))}
+	}
+	field, ok := (&object.ErrorValue{Err: err}).Attribute(name.Value)
+	if !ok {
+		return &object.Error{Error: "unknown error field " + name.Value}
+	}
+	if field == nil {
+		return NULL
+	}
+	return field
+}
@@ -4,9 +4,29 @@ import (
 	"fmt"
 	"interpreter/internal/ast"
 	"interpreter/internal/object"
+	"interpreter/internal/token"
+	"math"
+	"slices"
 )
 
+// Eval evaluates node in env. Errors raised or error values created while
+// evaluating node that do not have a position yet are given the token of node.
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	ret := eval(node, env)
+	var err *object.Error
+	switch ret := ret.(type) {
+	case *object.Error:
+		err = ret
+	case *object.ErrorValue:
+		err = ret.Err
+	}
+	if err != nil && err.Token.Line == 0 && node != nil {
+		err.Token = node.Pos()
+	}
+	return ret
+}
+
+func eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 
 	case *ast.Program:
@@ -16,12 +36,16 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return &object.Integer{Value: node.Value}
 	case *ast.FloatLiteral:
 		return &object.Float{Value: node.Value}
+	case *ast.ByteLiteral:
+		return &object.Byte{Value: node.Value}
 	case *ast.ArrayLiteral:
 		elements := evalParameters(node.Values, env)
 		if len(elements) == 1 && elements[0].Type() == object.ERROR_OBJ {
 			return elements[0]
 		}
 		return &object.Array{Elements: elements}
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
 	case *ast.BoolLiteral:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.StringLiteral:
@@ -33,8 +57,8 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return val
 		}
 
-		if stdFunc, ok := stdFunc[node.Value]; ok {
-			return stdFunc
+		if member, ok := prelude.Member(node.Value); ok {
+			return member
 		}
 		return &object.Error{Error: fmt.Sprintf("identifier not found: " + node.Value)}
 	// expressions
@@ -65,6 +89,14 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			default:
 				return &object.Error{Error: fmt.Sprintf("operator - unsuported for %s", right.Type())}
 			}
+		case "~":
+			switch right := right.(type) {
+			case *object.Integer:
+				return &object.Integer{Value: ^right.Value}
+			case *object.Byte:
+				return &object.Byte{Value: ^right.Value}
+			}
+			return &object.Error{Error: fmt.Sprintf("operator ~ unsupported for %s", right.Type())}
 		case "!":
 			switch right {
 			case TRUE:
@@ -78,6 +110,8 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			}
 		}
 		return &object.Error{Error: "unsupported prefix operator"}
+	case *ast.UpdateExpression:
+		return evalUpdateExpression(node, env)
 	case *ast.CallExpression:
 		function := Eval(node.FunctionIdentifer, env)
 		if function.Type() == object.ERROR_OBJ {
@@ -87,7 +121,11 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if len(params) == 1 && params[0].Type() == object.ERROR_OBJ {
 			return params[0]
 		}
-		return evalFunction(function, params)
+		named, err := evalNamedArguments(node.Parameters, env)
+		if err != nil {
+			return err
+		}
+		return evalFunction(function, params, named, env, node.Token)
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if left.Type() == object.ERROR_OBJ {
@@ -98,68 +136,115 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return index
 		}
 		return evalIndexExpression(left, index)
+	case *ast.MemberExpression:
+		obj := Eval(node.Object, env)
+		if isError(obj) {
+			return obj
+		}
+		return evalMemberExpression(obj, node.Member.Value)
 	case *ast.BlockStatement:
 		var ret object.Object
+		blockEnv := object.NewEnclosedEnvironment(env)
 		for _, statement := range node.Statements {
-			ret = Eval(statement, env)
+			ret = Eval(statement, blockEnv)
 			if ret != nil {
-				if ret.Type() == object.RETURN_VALUE_OBJ || ret.Type() == object.ERROR_OBJ {
+				switch ret.Type() {
+				case object.RETURN_VALUE_OBJ, object.ERROR_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
 					return ret
 				}
 			}
 		}
 		return ret
+	case *ast.FunctionLiteral:
+		return &object.Function{
+			Params:     node.ParameterList,
+			ReturnType: node.ReturnType,
+			Body:       node.Body,
+			Env:        env,
+		}
 	case *ast.FunctionStatement:
 		function := &object.Function{
-			Params: node.ParameterList,
-			Body:   node.Body,
-			Env:    env,
+			Name:       node.Identifier.Value,
+			Params:     node.ParameterList,
+			ReturnType: node.ReturnType,
+			Body:       node.Body,
+			Env:        env,
 		}
 		env.Set(node.Identifier.Value, function)
 		return function
 	case *ast.IfStatement:
 		condition := Eval(node.Condition, env)
-		if condition.Type() == object.ERROR_OBJ {
+		if isError(condition) {
 			return condition
 		}
+		var ret object.Object
 		if isTrue(condition) {
-			return Eval(node.Body, env)
-		} else {
-			if node.Alternative != nil {
-				return Eval(node.Alternative, env)
-			}
+			ret = Eval(node.Body, env)
+		} else if node.Alternative != nil {
+			ret = Eval(node.Alternative, env)
 		}
+		if ret == nil {
+			return NULL
+		}
+		return ret
 	case *ast.ReturnStatement:
 		val := Eval(node.Value, env)
 		if val.Type() == object.ERROR_OBJ {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
+	case *ast.PropagateExpression:
+		return evalPropagateExpression(node, env)
+	case *ast.TryStatement:
+		return evalTryStatement(node, env)
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node, env)
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
+	case *ast.StructStatement:
+		return evalStructStatement(node, env)
+	case *ast.ClassStatement:
+		return evalClassStatement(node, env)
+	case *ast.SuperExpression:
+		return evalSuperExpression(env)
 	case *ast.VarStatement:
+		return evalVarStatement(node, env)
+	case *ast.AssignStatement:
 		value := Eval(node.Value, env)
+		if isError(value) {
+			return value
+		}
 		if value == nil {
-			env.Set(node.Identifier.Value, nil)
-			return nil
+			value = NULL
 		}
-		if value.Type() == object.ERROR_OBJ {
-			return value
+		if node.Operator != "" {
+			return evalCompoundAssignment(node, value, env)
 		}
-		env.Set(node.Identifier.Value, value)
+		return evalAssignment(node.Target, value, env)
 	case *ast.WhileStatement:
 		var ret object.Object
+		var stop bool
 		for {
 			condition := Eval(node.Condition, env)
+			if isError(condition) {
+				return condition
+			}
 			if !isTrue(condition) {
 				break
 			}
-			ret = Eval(&node.Body, env)
-			if ret != nil {
-				if ret.Type() == object.RETURN_VALUE_OBJ || ret.Type() == object.ERROR_OBJ {
-					return ret
-				}
+			if ret, stop = loopControl(Eval(&node.Body, env), node.Label); stop {
+				return ret
 			}
 		}
 		return ret
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+	case *ast.ForInStatement:
+		return evalForInStatement(node, env)
+	case *ast.BreakStatement:
+		return &object.Break{Label: labelName(node.Label)}
+	case *ast.ContinueStatement:
+		return &object.Continue{Label: labelName(node.Label)}
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
 	}
@@ -174,13 +259,118 @@ func evalProgram(node *ast.Program, env *object.Environment) object.Object {
 		case *object.ReturnValue:
 			return res.Value
 		case *object.Error:
+			if res.Propagating != nil {
+				return res.Propagating
+			}
 			return res
 		}
 	}
 	return ret
 }
 
+func evalForStatement(node *ast.ForStatement, env *object.Environment) object.Object {
+	loopEnv := object.NewEnclosedEnvironment(env)
+	if node.Init != nil {
+		if init := Eval(node.Init, loopEnv); isError(init) {
+			return init
+		}
+	}
+	var ret object.Object
+	var stop bool
+	for {
+		if node.Condition != nil {
+			condition := Eval(node.Condition, loopEnv)
+			if isError(condition) {
+				return condition
+			}
+			if !isTrue(condition) {
+				break
+			}
+		}
+		if ret, stop = loopControl(Eval(node.Body, loopEnv), node.Label); stop {
+			return ret
+		}
+		if node.Post != nil {
+			if post := Eval(node.Post, loopEnv); isError(post) {
+				return post
+			}
+		}
+	}
+	return ret
+}
+
+func evalForInStatement(node *ast.ForInStatement, env *object.Environment) object.Object {
+	iterable := Eval(node.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+	var elements []object.Object
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		elements = iterable.Elements
+	case *object.Hash:
+		for _, pair := range iterable.Entries() {
+			elements = append(elements, pair.Key)
+		}
+	case *object.String:
+		elements = bytesToObjects([]byte(iterable.Value))
+	case *object.Bytes:
+		elements = bytesToObjects(iterable.Value)
+	default:
+		return &object.Error{Error: fmt.Sprintf("cannot iterate over %s", typeOf(iterable))}
+	}
+	var ret object.Object
+	var stop bool
+	for _, element := range elements {
+		loopEnv := object.NewEnclosedEnvironment(env)
+		loopEnv.Set(node.Variable.Value, element)
+		if ret, stop = loopControl(Eval(node.Body, loopEnv), node.Label); stop {
+			return ret
+		}
+	}
+	return ret
+}
+
+// loopControl inspects the result of a single iteration of the loop labelled
+// label. It reports whether the loop has to stop and the value the loop
+// evaluates to (or propagates further up) in that case.
+func loopControl(ret object.Object, label *ast.IdentifierExpression) (object.Object, bool) {
+	switch ret := ret.(type) {
+	case *object.Break:
+		if ret.Label == "" || ret.Label == labelName(label) {
+			return nil, true
+		}
+		return ret, true
+	case *object.Continue:
+		if ret.Label == "" || ret.Label == labelName(label) {
+			return nil, false
+		}
+		return ret, true
+	case *object.ReturnValue, *object.Error:
+		return ret, true
+	}
+	return ret, false
+}
+
+func labelName(label *ast.IdentifierExpression) string {
+	if label == nil {
+		return ""
+	}
+	return label.Value
+}
+
 func evalInfixExpression(left object.Object, right object.Object, operator string) object.Object {
+	// bytes wrap around when combined with each other and widen to the type
+	// of the other operand otherwise
+	if l, ok := left.(*object.Byte); ok {
+		if r, ok := right.(*object.Byte); ok {
+			return evalByteInfixExpression(l, r, operator)
+		}
+		left = &object.Integer{Value: int64(l.Value)}
+	}
+	if r, ok := right.(*object.Byte); ok {
+		right = &object.Integer{Value: int64(r.Value)}
+	}
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		l := left.(*object.Integer).Value
@@ -191,13 +381,44 @@ func evalInfixExpression(left object.Object, right object.Object, operator strin
 		case "-":
 			return &object.Integer{Value: l - r}
 		case "/":
+			if r == 0 {
+				return &object.Error{Error: "division by zero"}
+			}
 			return &object.Integer{Value: l / r}
 		case "*":
 			return &object.Integer{Value: l * r}
+		case "%":
+			if r == 0 {
+				return &object.Error{Error: "division by zero"}
+			}
+			return &object.Integer{Value: l % r}
+		case "**":
+			if r < 0 {
+				return &object.Float{Value: math.Pow(float64(l), float64(r))}
+			}
+			return &object.Integer{Value: intPow(l, r)}
+		case "&":
+			return &object.Integer{Value: l & r}
+		case "|":
+			return &object.Integer{Value: l | r}
+		case "^":
+			return &object.Integer{Value: l ^ r}
+		case "<<", ">>":
+			if r < 0 {
+				return &object.Error{Error: fmt.Sprintf("negative shift count %d", r)}
+			}
+			if operator == "<<" {
+				return &object.Integer{Value: l << r}
+			}
+			return &object.Integer{Value: l >> r}
 		case ">":
 			return nativeBoolToBooleanObject(l > r)
 		case "<":
 			return nativeBoolToBooleanObject(l < r)
+		case ">=":
+			return nativeBoolToBooleanObject(l >= r)
+		case "<=":
+			return nativeBoolToBooleanObject(l <= r)
 		case "==":
 			return nativeBoolToBooleanObject(l == r)
 		case "!=":
@@ -228,13 +449,27 @@ func evalInfixExpression(left object.Object, right object.Object, operator strin
 		case "-":
 			return &object.Float{Value: l - r}
 		case "/":
+			if r == 0 {
+				return &object.Error{Error: "division by zero"}
+			}
 			return &object.Float{Value: l / r}
 		case "*":
 			return &object.Float{Value: l * r}
+		case "%":
+			if r == 0 {
+				return &object.Error{Error: "division by zero"}
+			}
+			return &object.Float{Value: math.Mod(l, r)}
+		case "**":
+			return &object.Float{Value: math.Pow(l, r)}
 		case ">":
 			return nativeBoolToBooleanObject(l > r)
 		case "<":
 			return nativeBoolToBooleanObject(l < r)
+		case ">=":
+			return nativeBoolToBooleanObject(l >= r)
+		case "<=":
+			return nativeBoolToBooleanObject(l <= r)
 		case "==":
 			return nativeBoolToBooleanObject(l == r)
 		case "!=":
@@ -257,24 +492,62 @@ func evalInfixExpression(left object.Object, right object.Object, operator strin
 		default:
 			return &object.Error{Error: "could not apply " + operator + "to bool literal"}
 		}
-	case operator == "==":
-		return nativeBoolToBooleanObject(left == right)
-	case operator == "!=":
-		return nativeBoolToBooleanObject(left != right)
-	case left.Type() != right.Type():
-		return &object.Error{Error: "type mismatch"}
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
-		if operator != "+" {
-			return &object.Error{Error: "operator other than + not allowed for strings"}
-		}
 		l := left.(*object.String).Value
 		r := right.(*object.String).Value
-		return &object.String{Value: l + r}
+		switch operator {
+		case "+":
+			return &object.String{Value: l + r}
+		case ">":
+			return nativeBoolToBooleanObject(l > r)
+		case "<":
+			return nativeBoolToBooleanObject(l < r)
+		case ">=":
+			return nativeBoolToBooleanObject(l >= r)
+		case "<=":
+			return nativeBoolToBooleanObject(l <= r)
+		case "==":
+			return nativeBoolToBooleanObject(l == r)
+		case "!=":
+			return nativeBoolToBooleanObject(l != r)
+		default:
+			return &object.Error{Error: "unknown operator: " + operator}
+		}
+	case operator == "==" || operator == "!=":
+		l, lok := left.(*object.Struct)
+		r, rok := right.(*object.Struct)
+		if lok && rok {
+			return nativeBoolToBooleanObject(structsEqual(l, r) == (operator == "=="))
+		}
+		return nativeBoolToBooleanObject((left == right) == (operator == "=="))
+	case left.Type() != right.Type():
+		return &object.Error{Error: "type mismatch"}
 	default:
 		return &object.Error{Error: "unknown error "}
 	}
 }
 
+func evalByteInfixExpression(left, right *object.Byte, operator string) object.Object {
+	result := evalInfixExpression(&object.Integer{Value: int64(left.Value)}, &object.Integer{Value: int64(right.Value)}, operator)
+	if integer, ok := result.(*object.Integer); ok {
+		return &object.Byte{Value: byte(integer.Value)}
+	}
+	return result
+}
+
+// intPow raises base to the non-negative power exp by repeated squaring.
+func intPow(base, exp int64) int64 {
+	result := int64(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			result *= base
+		}
+		base *= base
+		exp >>= 1
+	}
+	return result
+}
+
 var (
 	NULL  = &object.Null{}
 	TRUE  = &object.Boolean{Value: true}
@@ -291,37 +564,244 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 func evalParameters(params []ast.Expression, env *object.Environment) []object.Object {
 	var ret []object.Object
 	for _, p := range params {
+		if _, ok := p.(*ast.NamedArgument); ok {
+			continue
+		}
+		spread, isSpread := p.(*ast.SpreadExpression)
+		if isSpread {
+			p = spread.Value
+		}
 		eval := Eval(p, env)
+		if eval == nil {
+			eval = NULL
+		}
 		if eval.Type() == object.ERROR_OBJ {
 			return []object.Object{eval}
 		}
-		ret = append(ret, eval)
+		if !isSpread {
+			ret = append(ret, eval)
+			continue
+		}
+		arr, ok := eval.(*object.Array)
+		if !ok {
+			return []object.Object{&object.Error{Error: fmt.Sprintf("cannot spread %s, expected ARRAY", eval.Type()), Token: spread.Token}}
+		}
+		ret = append(ret, arr.Elements...)
 	}
 	return ret
 }
 
-func evalFunction(fn object.Object, params []object.Object) object.Object {
+type namedArgument struct {
+	name  string
+	value object.Object
+}
+
+// evalNamedArguments evaluates the named arguments of a call, which follow
+// its positional ones.
+func evalNamedArguments(args []ast.Expression, env *object.Environment) ([]namedArgument, object.Object) {
+	var named []namedArgument
+	for _, arg := range args {
+		arg, ok := arg.(*ast.NamedArgument)
+		if !ok {
+			continue
+		}
+		value := Eval(arg.Value, env)
+		if isError(value) {
+			return nil, value
+		}
+		named = append(named, namedArgument{name: arg.Name, value: value})
+	}
+	return named, nil
+}
+
+// evalFunction calls fn with params and named arguments from env, where call
+// is the token of the call expression. Errors raised inside the call record
+// the call stack.
+func evalFunction(fn object.Object, params []object.Object, named []namedArgument, env *object.Environment, call token.Token) object.Object {
 	switch funcc := fn.(type) {
 	case *object.Function:
-		newEnv := expandEnv(funcc, params)
-		ev := Eval(funcc.Body, newEnv)
-		if retVal, ok := ev.(*object.ReturnValue); ok {
-			return retVal.Value
+		frame := &object.Frame{Function: funcc.Name, Token: call, Caller: env.Frame()}
+		ret := callFunction(funcc, params, named, frame)
+		if err, ok := ret.(*object.Error); ok && err.Stack == nil {
+			err.Stack = frame.Stack()
 		}
-		return ev
+		return ret
+	case *object.StructType:
+		frame := &object.Frame{Function: funcc.Name, Token: call, Caller: env.Frame()}
+		return construct(funcc, params, named, frame)
+	case *object.Class:
+		return instantiate(funcc, params, named, env, call)
 	case *object.StdFunction:
+		if len(named) != 0 {
+			return &object.Error{Error: "builtin functions do not accept named arguments"}
+		}
+		if funcc.EnvFun != nil {
+			return funcc.EnvFun(env, params...)
+		}
 		return funcc.Fun(params...)
 	default:
 		return &object.Error{Error: "not a func"}
 	}
 }
 
-func expandEnv(fn *object.Function, params []object.Object) *object.Environment {
-	env := object.NewEnclosedEnvironment(fn.Env)
-	for i, param := range fn.Params {
-		env.Set(param.Value, params[i])
+func callFunction(fn *object.Function, params []object.Object, named []namedArgument, frame *object.Frame) object.Object {
+	newEnv, err := expandEnv(fn, params, named, frame)
+	if err != nil {
+		return err
+	}
+	ev := Eval(fn.Body, newEnv)
+	if retVal, ok := ev.(*object.ReturnValue); ok {
+		ev = retVal.Value
+	}
+	if err, ok := ev.(*object.Error); ok && err.Propagating != nil {
+		return err.Propagating
+	}
+	// error values can be returned whatever the declared return type
+	if _, ok := ev.(*object.ErrorValue); ok {
+		return ev
+	}
+	if typ, ok := declaredTypes[fn.ReturnType.Type]; ok && !isError(ev) {
+		converted, ok := convertTo(ev, typ)
+		if !ok {
+			return typeError(fn.ReturnType, "cannot return %s from function returning %s", typeName(ev), typ)
+		}
+		return converted
+	}
+	return ev
+}
+
+// expandEnv binds the arguments of a call of fn in a new environment. The
+// positional arguments fill the parameters in order, the named ones the
+// parameters they name, and the defaults of the parameters left are evaluated
+// in the new environment, so they can refer to the parameters before them.
+func expandEnv(fn *object.Function, params []object.Object, named []namedArgument, frame *object.Frame) (*object.Environment, *object.Error) {
+	env := object.NewFrameEnvironment(fn.Env, frame)
+	if fn.Receiver != nil {
+		params = append([]object.Object{fn.Receiver}, params...)
+	}
+	fixed := fn.Params
+	var rest *ast.IdentifierExpression
+	if n := len(fn.Params); n > 0 && fn.Params[n-1].Variadic {
+		fixed, rest = fn.Params[:n-1], &fn.Params[n-1]
+	}
+	if rest == nil && len(params) > len(fixed) {
+		return nil, arityError(fn, len(params))
+	}
+	args := make([]object.Object, len(fixed))
+	copy(args, params)
+	for _, arg := range named {
+		i := slices.IndexFunc(fixed, func(param ast.IdentifierExpression) bool { return param.Value == arg.name })
+		if i == -1 {
+			return nil, &object.Error{Error: fmt.Sprintf("function %s has no parameter named %s", functionName(fn), arg.name)}
+		}
+		if args[i] != nil {
+			return nil, &object.Error{Error: fmt.Sprintf("argument %s is passed more than once", arg.name)}
+		}
+		args[i] = arg.value
+	}
+	for i, param := range fixed {
+		arg := args[i]
+		if arg == nil && param.Default != nil {
+			arg = Eval(param.Default, env)
+			if err, ok := arg.(*object.Error); ok {
+				return nil, err
+			}
+		}
+		if arg == nil {
+			if len(named) == 0 {
+				return nil, arityError(fn, len(params))
+			}
+			return nil, &object.Error{Error: fmt.Sprintf("function %s is missing argument %s", functionName(fn), param.Value)}
+		}
+		value, err := parameterValue(param, arg)
+		if err != nil {
+			return nil, err
+		}
+		if typ, typed := declaredTypes[param.Type.Type]; typed {
+			env.SetTyped(param.Value, value, typ)
+		} else {
+			env.Set(param.Value, value)
+		}
+	}
+	if rest != nil {
+		elements := []object.Object{}
+		for i := len(fixed); i < len(params); i++ {
+			value, err := parameterValue(*rest, params[i])
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, value)
+		}
+		env.Set(rest.Value, &object.Array{Elements: elements})
+	}
+	return env, nil
+}
+
+// parameterValue converts arg to the declared type of param. The elements of
+// a typed variadic parameter are converted one by one.
+func parameterValue(param ast.IdentifierExpression, arg object.Object) (object.Object, *object.Error) {
+	typ, typed := declaredTypes[param.Type.Type]
+	if !typed {
+		return arg, nil
+	}
+	value, ok := convertTo(arg, typ)
+	if !ok {
+		return nil, typeError(param.Token, "cannot pass %s as parameter %s of type %s", typeName(arg), param.Value, typ)
+	}
+	return value, nil
+}
+
+// arityError reports a call of fn with got positional arguments, when it
+// takes fewer or needs more of them.
+func arityError(fn *object.Function, got int) *object.Error {
+	min, max := 0, 0
+	for _, param := range fn.Params {
+		if param.Variadic {
+			max = -1
+			break
+		}
+		if param.Default == nil {
+			min++
+		}
+		max++
+	}
+	// the receiver of a bound method is not passed by the caller
+	if fn.Receiver != nil {
+		min, got = min-1, got-1
+		if max != -1 {
+			max--
+		}
+	}
+	want, bound := min, "at least "
+	if got > min {
+		want, bound = max, "at most "
+	}
+	if min == max {
+		bound = ""
+	}
+	arguments := "arguments"
+	if want == 1 {
+		arguments = "argument"
+	}
+	return &object.Error{Error: fmt.Sprintf("function %s expects %s%d %s, got %d", functionName(fn), bound, want, arguments, got)}
+}
+
+func functionName(fn *object.Function) string {
+	if fn.Name == "" {
+		return fn.Inspect()
 	}
-	return env
+	return fn.Name
+}
+
+func isError(obj object.Object) bool {
+	return obj != nil && obj.Type() == object.ERROR_OBJ
+}
+
+func typeOf(obj object.Object) object.ObjectType {
+	if obj == nil {
+		return object.NULL_OBJ
+	}
+	return obj.Type()
 }
 
 func isTrue(condition object.Object) bool {
@@ -337,18 +817,254 @@ func isTrue(condition object.Object) bool {
 	}
 }
 
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+	for i, keyNode := range node.Keys {
+		key := Eval(keyNode, env)
+		if isError(key) {
+			return key
+		}
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return &object.Error{Error: fmt.Sprintf("unusable as hash key: %s", typeOf(key))}
+		}
+		value := Eval(node.Values[i], env)
+		if isError(value) {
+			return value
+		}
+		if value == nil {
+			value = NULL
+		}
+		hash.Set(hashKey, value)
+	}
+	return hash
+}
+
 func evalIndexExpression(left, index object.Object) object.Object {
-	if left.Type() != object.ARRAY_OBJ {
-		return &object.Error{Error: "index expression must be applied to ARRAY object"}
+	switch left := left.(type) {
+	case *object.Array:
+		return evalArrayIndexExpression(left, index)
+	case *object.Hash:
+		return evalHashIndexExpression(left, index)
+	case *object.String:
+		idx, err := arrayIndex(index, len(left.Value))
+		if err != nil {
+			return err
+		}
+		return &object.Byte{Value: left.Value[idx]}
+	case *object.Bytes:
+		idx, err := arrayIndex(index, len(left.Value))
+		if err != nil {
+			return err
+		}
+		return &object.Byte{Value: left.Value[idx]}
+	case *object.ErrorValue:
+		return errorField(left.Err, index)
+	case *object.Namespace:
+		return namespaceMember(left, index)
+	default:
+		return &object.Error{Error: "index expression must be applied to ARRAY, HASH, STRING, BYTES, ERROR_VALUE or NAMESPACE object"}
 	}
-	if index.Type() != object.INTEGER_OBJ {
-		return &object.Error{Error: "index number must be INTEGER"}
+}
+
+func bytesToObjects(value []byte) []object.Object {
+	elements := make([]object.Object, len(value))
+	for i, b := range value {
+		elements[i] = &object.Byte{Value: b}
 	}
-	arrayObject := left.(*object.Array)
-	idx := index.(*object.Integer).Value
-	max := int64(len(arrayObject.Elements) - 1)
-	if idx < 0 || idx > max {
-		return NULL
+	return elements
+}
+
+func evalHashIndexExpression(hash *object.Hash, index object.Object) object.Object {
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return &object.Error{Error: fmt.Sprintf("unusable as hash key: %s", typeOf(index))}
+	}
+	if value, ok := hash.Get(key); ok {
+		return value
+	}
+	return NULL
+}
+
+func evalArrayIndexExpression(arrayObject *object.Array, index object.Object) object.Object {
+	idx, err := arrayIndex(index, len(arrayObject.Elements))
+	if err != nil {
+		return err
 	}
 	return arrayObject.Elements[idx]
 }
+
+// arrayIndex resolves index against an array of the given length for both
+// reads and writes. Negative indexes count back from the end of the array, so
+// -1 is the last element; anything outside the array is an error.
+func arrayIndex(index object.Object, length int) (int64, *object.Error) {
+	integer, ok := index.(*object.Integer)
+	if !ok {
+		return 0, &object.Error{Error: "index number must be INTEGER"}
+	}
+	idx := integer.Value
+	if idx < 0 {
+		idx += int64(length)
+	}
+	if idx < 0 || idx >= int64(length) {
+		return 0, &object.Error{Error: fmt.Sprintf("index out of range: %d with length %d", integer.Value, length)}
+	}
+	return idx, nil
+}
+
+func evalAssignment(target ast.Expression, value object.Object, env *object.Environment) object.Object {
+	switch target := target.(type) {
+	case *ast.IdentifierExpression:
+		return assignVariable(target, value, env)
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return index
+		}
+		return evalIndexAssignment(left, index, value)
+	case *ast.MemberExpression:
+		obj := Eval(target.Object, env)
+		if isError(obj) {
+			return obj
+		}
+		return evalMemberAssignment(obj, target.Member, value)
+	default:
+		return &object.Error{Error: fmt.Sprintf("invalid assignment target %s", target)}
+	}
+}
+
+func evalIndexAssignment(left, index, value object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		idx, err := arrayIndex(index, len(left.Elements))
+		if err != nil {
+			return err
+		}
+		left.Elements[idx] = value
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return &object.Error{Error: fmt.Sprintf("unusable as hash key: %s", typeOf(index))}
+		}
+		left.Set(key, value)
+	case *object.Bytes:
+		idx, err := arrayIndex(index, len(left.Value))
+		if err != nil {
+			return err
+		}
+		b, ok := toByte(value)
+		if !ok {
+			return &object.Error{Error: fmt.Sprintf("cannot store %s in BYTES", value.Inspect())}
+		}
+		left.Value[idx] = b
+	default:
+		return &object.Error{Error: "index assignment must be applied to ARRAY, HASH or BYTES object"}
+	}
+	return nil
+}
+
+func evalCompoundAssignment(node *ast.AssignStatement, value object.Object, env *object.Environment) object.Object {
+	_, updated := evalUpdate(node.Target, env, func(current object.Object) object.Object {
+		return evalInfixExpression(current, value, node.Operator)
+	})
+	if isError(updated) {
+		return updated
+	}
+	return nil
+}
+
+func evalUpdateExpression(node *ast.UpdateExpression, env *object.Environment) object.Object {
+	operator := node.Operator[:1]
+	current, updated := evalUpdate(node.Target, env, func(current object.Object) object.Object {
+		switch current.Type() {
+		case object.BYTE_OBJ:
+			return evalInfixExpression(current, &object.Byte{Value: 1}, operator)
+		case object.INTEGER_OBJ, object.FLOAT_OBJ:
+			return evalInfixExpression(current, &object.Integer{Value: 1}, operator)
+		default:
+			return &object.Error{Error: fmt.Sprintf("operator %s unsupported for %s", node.Operator, current.Type())}
+		}
+	})
+	if isError(updated) || !node.Postfix {
+		return updated
+	}
+	return current
+}
+
+// evalUpdate replaces the value stored in target with the result of calling
+// update on it and returns both the previous and the new value. The operands
+// of an index target are evaluated only once.
+func evalUpdate(target ast.Expression, env *object.Environment, update func(object.Object) object.Object) (object.Object, object.Object) {
+	switch target := target.(type) {
+	case *ast.IdentifierExpression:
+		current, ok := env.Get(target.Value)
+		if !ok {
+			return nil, &object.Error{Error: "assignment to undeclared variable " + target.Value}
+		}
+		updated := update(current)
+		if isError(updated) {
+			return nil, updated
+		}
+		if err := assignVariable(target, updated, env); err != nil {
+			return nil, err
+		}
+		return current, updated
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return nil, left
+		}
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return nil, index
+		}
+		current := evalIndexExpression(left, index)
+		if isError(current) {
+			return nil, current
+		}
+		updated := update(current)
+		if isError(updated) {
+			return nil, updated
+		}
+		if err := evalIndexAssignment(left, index, updated); err != nil {
+			return nil, err
+		}
+		return current, updated
+	case *ast.MemberExpression:
+		obj := Eval(target.Object, env)
+		if isError(obj) {
+			return nil, obj
+		}
+		current := evalMemberExpression(obj, target.Member.Value)
+		if isError(current) {
+			return nil, current
+		}
+		updated := update(current)
+		if isError(updated) {
+			return nil, updated
+		}
+		if err := evalMemberAssignment(obj, target.Member, updated); err != nil {
+			return nil, err
+		}
+		return current, updated
+	default:
+		return nil, &object.Error{Error: fmt.Sprintf("invalid assignment target %s", target)}
+	}
+}
+
+// toByte converts a byte or an integer in the range 0 to 255 to a byte.
+func toByte(obj object.Object) (byte, bool) {
+	switch obj := obj.(type) {
+	case *object.Byte:
+		return obj.Value, true
+	case *object.Integer:
+		if obj.Value >= 0 && obj.Value <= 255 {
+			return byte(obj.Value), true
+		}
+	}
+	return 0, false
+}
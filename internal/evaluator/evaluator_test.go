@@ -3,8 +3,11 @@ package evaluator_test
 import (
 	"interpreter/internal/evaluator"
 	"interpreter/internal/lexer"
+	_ "interpreter/internal/library/math"
 	"interpreter/internal/object"
 	"interpreter/internal/parser"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -114,6 +117,581 @@ func TestVarEvaluation(t *testing.T) {
 	}
 }
 
+func TestAssignmentEvaluation(t *testing.T) {
+	testCases := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{
+			input:       "var x = 1; fun f() { x = x + 1; } f(); f(); x;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "3",
+		},
+		{
+			input:       "var x = 1; if true { x = 5; } x;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "5",
+		},
+		{
+			input:       "var x = 1; if true { var x = 5; } x;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "1",
+		},
+		{
+			input:       "var s = 0; for var i = 0; i < 3; i = i + 1 { var d = i * 2; s = s + d; } s;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "6",
+		},
+		{
+			input:       "fun f(a) { var a = a + 1; return a; } f(1);",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "2",
+		},
+		{
+			input:       "var x; x;",
+			returnType:  object.NULL_OBJ,
+			returnValue: "null",
+		},
+		{
+			input:       "y = 1;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: assignment to undeclared variable y",
+		},
+		{
+			input:       "fun f() { z = 1; } f();",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: assignment to undeclared variable z",
+		},
+		{
+			input:       "var x = 1; var x = 2;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: variable x is already declared in this scope",
+		},
+	}
+	for i, tC := range testCases {
+		eval := evaluate(t, i, tC.input)
+		checkTypeAndValue(t, i, eval, tC.returnType, tC.returnValue)
+	}
+}
+
+func TestOperatorEvaluation(t *testing.T) {
+	testCases := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{
+			input:       "7 % 3;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "1",
+		},
+		{
+			input:       "-7 % 3;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "-1",
+		},
+		{
+			input:       "7.5 % 2;",
+			returnType:  object.FLOAT_OBJ,
+			returnValue: "1.500000",
+		},
+		{
+			input:       "2 ** 10;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "1024",
+		},
+		{
+			input:       "2 ** 3 ** 2;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "512",
+		},
+		{
+			input:       "-2 ** 2;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "-4",
+		},
+		{
+			input:       "2 ** -1;",
+			returnType:  object.FLOAT_OBJ,
+			returnValue: "0.500000",
+		},
+		{
+			input:       "2.0 ** 0.5 > 1.41;",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "true",
+		},
+		{
+			input:       "6 & 3;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "2",
+		},
+		{
+			input:       "6 | 3;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "7",
+		},
+		{
+			input:       "6 ^ 3;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "5",
+		},
+		{
+			input:       "~5;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "-6",
+		},
+		{
+			input:       "1 << 4;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "16",
+		},
+		{
+			input:       "-16 >> 2;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "-4",
+		},
+		{
+			input:       "3 >= 3;",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "true",
+		},
+		{
+			input:       "2 <= 1;",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "false",
+		},
+		{
+			input:       "2.5 >= 2;",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "true",
+		},
+		{
+			input:       "1 <= 0.5;",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "false",
+		},
+		{
+			input:       "1 == 1 and 2 != 3;",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "true",
+		},
+		{
+			input:       "\"abc\" == \"abc\";",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "true",
+		},
+		{
+			input:       "\"abc\" != \"abd\";",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "true",
+		},
+		{
+			input:       "\"abc\" < \"abd\";",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "true",
+		},
+		{
+			input:       "\"b\" >= \"abc\";",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "true",
+		},
+		{
+			input:       "1 / 0;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: division by zero",
+		},
+		{
+			input:       "1 % 0;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: division by zero",
+		},
+		{
+			input:       "1.5 / 0;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: division by zero",
+		},
+		{
+			input:       "1 << -1;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: negative shift count -1",
+		},
+		{
+			input:       "~1.5;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: operator ~ unsupported for FLOAT",
+		},
+		{
+			input:       "1.5 & 1;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: unknown operator: &",
+		},
+		{
+			input:       "\"a\" - \"b\";",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: unknown operator: -",
+		},
+	}
+	for i, tC := range testCases {
+		eval := evaluate(t, i, tC.input)
+		checkTypeAndValue(t, i, eval, tC.returnType, tC.returnValue)
+	}
+}
+
+func TestByteEvaluation(t *testing.T) {
+	testCases := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{
+			input:       "'a';",
+			returnType:  object.BYTE_OBJ,
+			returnValue: "97",
+		},
+		{
+			input:       "'a' + 1b;",
+			returnType:  object.BYTE_OBJ,
+			returnValue: "98",
+		},
+		{
+			input:       "255b + 1b;",
+			returnType:  object.BYTE_OBJ,
+			returnValue: "0",
+		},
+		{
+			input:       "0b - 1b;",
+			returnType:  object.BYTE_OBJ,
+			returnValue: "255",
+		},
+		{
+			input:       "16b * 16b;",
+			returnType:  object.BYTE_OBJ,
+			returnValue: "0",
+		},
+		{
+			input:       "1b << 8b;",
+			returnType:  object.BYTE_OBJ,
+			returnValue: "0",
+		},
+		{
+			input:       "~0b;",
+			returnType:  object.BYTE_OBJ,
+			returnValue: "255",
+		},
+		{
+			input:       "255b + 1;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "256",
+		},
+		{
+			input:       "1.5 + 1b;",
+			returnType:  object.FLOAT_OBJ,
+			returnValue: "2.500000",
+		},
+		{
+			input:       "'a' < 'b';",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "true",
+		},
+		{
+			input:       "'a' == 97;",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "true",
+		},
+		{
+			input:       "var b = 255b; b++; b;",
+			returnType:  object.BYTE_OBJ,
+			returnValue: "0",
+		},
+		{
+			input:       "\"abc\"[1];",
+			returnType:  object.BYTE_OBJ,
+			returnValue: "98",
+		},
+		{
+			input:       "\"abc\"[-1] == 'c';",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "true",
+		},
+		{
+			input:       "var s = 0; for c in \"ab\" { s = s + c; } s;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "195",
+		},
+		{
+			input:       "var b = bytes(\"abc\"); b[0] = 'z'; b[1] = 66; b;",
+			returnType:  object.BYTES_OBJ,
+			returnValue: "b\"zBc\"",
+		},
+		{
+			input:       "var b = bytes(2); b[0]++; b;",
+			returnType:  object.BYTES_OBJ,
+			returnValue: "b\"\\x01\\x00\"",
+		},
+		{
+			input:       "var h = {'a': 1}; h[97b];",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "1",
+		},
+		{
+			input:       "1b / 0b;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: division by zero",
+		},
+		{
+			input:       "-'a';",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: operator - unsuported for BYTE",
+		},
+		{
+			input:       "var b = bytes(1); b[0] = 256;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot store 256 in BYTES",
+		},
+		{
+			input:       "var s = \"a\"; s[0] = 'b';",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: index assignment must be applied to ARRAY, HASH or BYTES object",
+		},
+		{
+			input:       "\"abc\"[3];",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: index out of range: 3 with length 3",
+		},
+	}
+	for i, tC := range testCases {
+		eval := evaluate(t, i, tC.input)
+		checkTypeAndValue(t, i, eval, tC.returnType, tC.returnValue)
+	}
+}
+
+func TestTypedEvaluation(t *testing.T) {
+	testCases := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{
+			input:       "int x = 5; x = x * 2; x;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "10",
+		},
+		{
+			input:       "float f = 1; f;",
+			returnType:  object.FLOAT_OBJ,
+			returnValue: "1.000000",
+		},
+		{
+			input:       "float f = 1.5; f = 2; f += 1; f;",
+			returnType:  object.FLOAT_OBJ,
+			returnValue: "3.000000",
+		},
+		{
+			input:       "int x; x;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "0",
+		},
+		{
+			input:       "string s; s;",
+			returnType:  object.STRING_OBJ,
+			returnValue: "",
+		},
+		{
+			input:       "bool b; b;",
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "false",
+		},
+		{
+			input:       "byte b = 'a'; b++; b;",
+			returnType:  object.BYTE_OBJ,
+			returnValue: "98",
+		},
+		{
+			input:       "int x = 1; if true { var x = \"shadow\"; } x;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "1",
+		},
+		{
+			input:       "fun add(int a, int b) int { return a + b; } add(1, 2);",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "3",
+		},
+		{
+			input:       "fun half(float f) float { return f / 2; } half(3);",
+			returnType:  object.FLOAT_OBJ,
+			returnValue: "1.500000",
+		},
+		{
+			input:       "fun id(x) { return x; } id(\"any\");",
+			returnType:  object.STRING_OBJ,
+			returnValue: "any",
+		},
+		{
+			input:       "var f = fun(int n) int { n = n + 1; return n; }; f(1);",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "2",
+		},
+		{
+			input:       "int x = \"a\";",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot assign STRING to x of type INTEGER",
+		},
+		{
+			input:       "int x = 1;\nx = 1.5;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot assign FLOAT to x of type INTEGER",
+		},
+		{
+			input:       "int x = 1; x += 0.5;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot assign FLOAT to x of type INTEGER",
+		},
+		{
+			input:       "string s; fun f() { s = 1; } f();",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot assign INTEGER to s of type STRING",
+		},
+		{
+			input:       "fun f(int n) { n = true; } f(1);",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot assign BOOLEAN to n of type INTEGER",
+		},
+		{
+			input:       "fun f(int n) { return n; } f(\"1\");",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot pass STRING as parameter n of type INTEGER",
+		},
+		{
+			input:       "fun f() int { return \"1\"; } f();",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot return STRING from function returning INTEGER",
+		},
+		{
+			input:       "fun f() int { } f();",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot return NULL from function returning INTEGER",
+		},
+	}
+	for i, tC := range testCases {
+		eval := evaluate(t, i, tC.input)
+		checkTypeAndValue(t, i, eval, tC.returnType, tC.returnValue)
+	}
+}
+
+func TestUpdateEvaluation(t *testing.T) {
+	testCases := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{
+			input:       "var i = 1; i++; i;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "2",
+		},
+		{
+			input:       "var i = 1; i++;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "1",
+		},
+		{
+			input:       "var i = 1; ++i;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "2",
+		},
+		{
+			input:       "var i = 1; var j = i-- + --i; j;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "0",
+		},
+		{
+			input:       "var f = 1.5; f++; f;",
+			returnType:  object.FLOAT_OBJ,
+			returnValue: "2.500000",
+		},
+		{
+			input:       "var a = [1, 2]; a[1]++; a;",
+			returnType:  object.ARRAY_OBJ,
+			returnValue: "[1, 3]",
+		},
+		{
+			input:       "var h = {\"n\": 1}; --h[\"n\"];",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "0",
+		},
+		{
+			input:       "var s = 0; for var i = 0; i < 4; i++ { s += i; } s;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "6",
+		},
+		{
+			input:       "var x = 10; x -= 3; x *= 2; x /= 7; x;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "2",
+		},
+		{
+			input:       "var x = 10; x %= 4; x;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "2",
+		},
+		{
+			input:       "var x = 1.0; x += 1; x;",
+			returnType:  object.FLOAT_OBJ,
+			returnValue: "2.000000",
+		},
+		{
+			input:       "var s = \"a\"; s += \"b\"; s;",
+			returnType:  object.STRING_OBJ,
+			returnValue: "ab",
+		},
+		{
+			input:       "var a = [1, 2]; var i = 0; a[i++] += 10; a;",
+			returnType:  object.ARRAY_OBJ,
+			returnValue: "[11, 2]",
+		},
+		{
+			input:       "var x = 1; fun f() { x += 1; } f(); x;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "2",
+		},
+		{
+			input:       "var s = \"a\"; s++;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: operator ++ unsupported for STRING",
+		},
+		{
+			input:       "var b = true; --b;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: operator -- unsupported for BOOLEAN",
+		},
+		{
+			input:       "y++;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: assignment to undeclared variable y",
+		},
+		{
+			input:       "y += 1;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: assignment to undeclared variable y",
+		},
+		{
+			input:       "var a = [1]; a[3]++;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: index out of range: 3 with length 1",
+		},
+		{
+			input:       "var x = 1; x %= 0;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: division by zero",
+		},
+	}
+	for i, tC := range testCases {
+		eval := evaluate(t, i, tC.input)
+		checkTypeAndValue(t, i, eval, tC.returnType, tC.returnValue)
+	}
+}
+
 func TestFunctionEvaluation(t *testing.T) {
 	testCases := []struct {
 		input       string
@@ -121,20 +699,376 @@ func TestFunctionEvaluation(t *testing.T) {
 		returnValue string
 	}{
 		{
-			input:       "fun a(x, y) { return x+y; } a(2,3);",
+			input:       "fun a(x, y) { return x+y; } a(2,3);",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "5",
+		},
+		{
+			input:       "fun a(b) { return b; } a(\"abc\");",
+			returnType:  object.STRING_OBJ,
+			returnValue: "abc",
+		},
+		{
+			input:       "fun a() { return 1; } a();",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "1",
+		},
+	}
+	for i, tC := range testCases {
+		eval := evaluate(t, i, tC.input)
+		checkTypeAndValue(t, i, eval, tC.returnType, tC.returnValue)
+	}
+}
+
+func TestVariadicEvaluation(t *testing.T) {
+	tests := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{`fun f(...rest) { return rest; } f();`, object.ARRAY_OBJ, "[]"},
+		{`fun f(...rest) { return rest; } f(1, 2, 3);`, object.ARRAY_OBJ, "[1, 2, 3]"},
+		{`fun f(a, ...rest) { return [a, rest]; } f(1, 2, 3);`, object.ARRAY_OBJ, "[1, [2, 3]]"},
+		{`fun f(a, ...rest) { return [a, rest]; } f(1);`, object.ARRAY_OBJ, "[1, []]"},
+		{`fun sum(...nums) { var s = 0; for n in nums { s += n; } return s; } sum(...[1, 2, 3]);`, object.INTEGER_OBJ, "6"},
+		{`fun f(a, b, c) { return a + b + c; } f(1, ...[2, 3]);`, object.INTEGER_OBJ, "6"},
+		{`fun f(a, b, c) { return a + b + c; } var args = [1, 2]; f(...args, 3);`, object.INTEGER_OBJ, "6"},
+		{`fun f(...rest) { return len(rest); } f(...[1, 2], ...[], 3);`, object.INTEGER_OBJ, "3"},
+		{`fun f(float ...xs) { return xs; } f(1, 2.5);`, object.ARRAY_OBJ, "[1.000000, 2.500000]"},
+		{`fun f(int ...xs) { return xs; } f(1, "2");`, object.ERROR_OBJ, "ERROR: cannot pass STRING as parameter xs of type INTEGER"},
+		{`len(...["abc"]);`, object.INTEGER_OBJ, "3"},
+		{`fun f(a) { return a; } f(...1);`, object.ERROR_OBJ, "ERROR: cannot spread INTEGER, expected ARRAY"},
+		{`fun f(a, b) { return a; } f(1);`, object.ERROR_OBJ, "ERROR: function f expects 2 arguments, got 1"},
+		{`fun f(a) { return a; } f(1, 2);`, object.ERROR_OBJ, "ERROR: function f expects 1 argument, got 2"},
+		{`fun f(a, b, ...rest) { return a; } f(1);`, object.ERROR_OBJ, "ERROR: function f expects at least 2 arguments, got 1"},
+		{`var f = fun() { return 1; }; f(1);`, object.ERROR_OBJ, "ERROR: function <fun> expects 0 arguments, got 1"},
+		{`fun f(a, b) { return a; } f(...[1, 2, 3]);`, object.ERROR_OBJ, "ERROR: function f expects 2 arguments, got 3"},
+		{`len();`, object.ERROR_OBJ, "ERROR: len function only accepts one parameter"},
+	}
+	for i, tt := range tests {
+		checkTypeAndValue(t, i, evaluate(t, i, tt.input), tt.returnType, tt.returnValue)
+	}
+}
+
+func TestDefaultsAndNamedArguments(t *testing.T) {
+	connect := `fun connect(host, port = 8080, verbose = false) { return [host, port, verbose]; } `
+	tests := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{connect + `connect("x");`, object.ARRAY_OBJ, "[x, 8080, false]"},
+		{connect + `connect("x", 80);`, object.ARRAY_OBJ, "[x, 80, false]"},
+		{connect + `connect("x", verbose = true);`, object.ARRAY_OBJ, "[x, 8080, true]"},
+		{connect + `connect(verbose = true, host = "y");`, object.ARRAY_OBJ, "[y, 8080, true]"},
+		{connect + `connect("x", 1, true);`, object.ARRAY_OBJ, "[x, 1, true]"},
+		{connect + `connect();`, object.ERROR_OBJ, "ERROR: function connect expects at least 1 argument, got 0"},
+		{connect + `connect("x", 1, true, 2);`, object.ERROR_OBJ, "ERROR: function connect expects at most 3 arguments, got 4"},
+		{connect + `connect(port = 1);`, object.ERROR_OBJ, "ERROR: function connect is missing argument host"},
+		{connect + `connect("x", timeout = 1);`, object.ERROR_OBJ, "ERROR: function connect has no parameter named timeout"},
+		{connect + `connect("x", host = "y");`, object.ERROR_OBJ, "ERROR: argument host is passed more than once"},
+		{connect + `connect("x", port = 1, port = 2);`, object.ERROR_OBJ, "ERROR: argument port is passed more than once"},
+		{`fun f(a, b = a * 2) { return b; } f(3);`, object.INTEGER_OBJ, "6"},
+		{`var n = 0; fun f(a = n) { return a; } n = 5; f();`, object.INTEGER_OBJ, "5"},
+		{`fun f(float x = 1) { return x; } f();`, object.FLOAT_OBJ, "1.000000"},
+		{`fun f(int x = "a") { return x; } f();`, object.ERROR_OBJ, "ERROR: cannot pass STRING as parameter x of type INTEGER"},
+		{`fun f(a = 1 / 0) { return a; } f();`, object.ERROR_OBJ, "ERROR: division by zero"},
+		{`fun f(a, b = 2, ...rest) { return [a, b, rest]; } f(1, 3, 4, 5);`, object.ARRAY_OBJ, "[1, 3, [4, 5]]"},
+		{`fun f(a, b = 2, ...rest) { return [a, b, rest]; } f(a = 1);`, object.ARRAY_OBJ, "[1, 2, []]"},
+		{`var f = fun(x = 1) { return x; }; f(x = 2);`, object.INTEGER_OBJ, "2"},
+		{`len(x = 1);`, object.ERROR_OBJ, "ERROR: builtin functions do not accept named arguments"},
+	}
+	for i, tt := range tests {
+		checkTypeAndValue(t, i, evaluate(t, i, tt.input), tt.returnType, tt.returnValue)
+	}
+}
+
+func TestClosureEvaluation(t *testing.T) {
+	testCases := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{
+			input:       "var double = fun(x) { return x * 2; }; double(21);",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "42",
+		},
+		{
+			input:       "fun apply(f, x) { return f(x); } apply(fun(n) { return n + 1; }, 1);",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "2",
+		},
+		{
+			input:       "fun adder(n) { return fun(x) { return x + n; }; } adder(3)(4);",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "7",
+		},
+		{
+			input:       "fun counter() { var c = 0; return fun() { c = c + 1; return c; }; } var next = counter(); next(); next(); next();",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "3",
+		},
+		{
+			input:       "fun counter() { var c = 0; return fun() { c = c + 1; return c; }; } var a = counter(); var b = counter(); a(); a(); b();",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "1",
+		},
+		{
+			input:       "var total = 0; for x in [1, 2, 3] { total = total + x; } total;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "6",
+		},
+		{
+			input:       "(fun(x) { return x; })(5);",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "5",
+		},
+	}
+	for i, tC := range testCases {
+		eval := evaluate(t, i, tC.input)
+		checkTypeAndValue(t, i, eval, tC.returnType, tC.returnValue)
+	}
+}
+
+func TestHashEvaluation(t *testing.T) {
+	testCases := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{
+			input:       `var h = {"a": 1, "b": 2}; h["b"];`,
 			returnType:  object.INTEGER_OBJ,
-			returnValue: "5",
+			returnValue: "2",
 		},
 		{
-			input:       "fun a(b) { return b; } a(\"abc\");",
+			input:       `var h = {1: "one", true: "yes"}; h[1] + h[true];`,
 			returnType:  object.STRING_OBJ,
-			returnValue: "abc",
+			returnValue: "oneyes",
 		},
 		{
-			input:       "fun a() { return 1; } a();",
+			input:       `var h = {"a": 1}; h["missing"];`,
+			returnType:  object.NULL_OBJ,
+			returnValue: "null",
+		},
+		{
+			input:       `{"z": 1, "a": 2, "m": 3};`,
+			returnType:  object.HASH_OBJ,
+			returnValue: "{z: 1, a: 2, m: 3}",
+		},
+		{
+			input:       `var order = ""; for k in {"z": 1, "a": 2, "m": 3} { order = order + k; } order;`,
+			returnType:  object.STRING_OBJ,
+			returnValue: "zam",
+		},
+		{
+			input:       `{"a": print()};`,
+			returnType:  object.HASH_OBJ,
+			returnValue: "{a: null}",
+		},
+		{
+			input:       `[print(), 1];`,
+			returnType:  object.ARRAY_OBJ,
+			returnValue: "[null, 1]",
+		},
+		{
+			input:       `var h = {[1]: 2};`,
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: unusable as hash key: ARRAY",
+		},
+		{
+			input:       `var h = {"a": 1}; h[[1]];`,
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: unusable as hash key: ARRAY",
+		},
+	}
+	for i, tC := range testCases {
+		eval := evaluate(t, i, tC.input)
+		checkTypeAndValue(t, i, eval, tC.returnType, tC.returnValue)
+	}
+}
+
+func TestIndexEvaluation(t *testing.T) {
+	testCases := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{
+			input:       "var a = [1, 2, 3]; a[1] = 5; a;",
+			returnType:  object.ARRAY_OBJ,
+			returnValue: "[1, 5, 3]",
+		},
+		{
+			input:       "var a = [1, 2, 3]; a[-1];",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "3",
+		},
+		{
+			input:       "var a = [1, 2, 3]; a[-3] = 0; a;",
+			returnType:  object.ARRAY_OBJ,
+			returnValue: "[0, 2, 3]",
+		},
+		{
+			input:       "var a = [1, 2, 3]; a[3];",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: index out of range: 3 with length 3",
+		},
+		{
+			input:       "var a = [1, 2, 3]; a[-4] = 1;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: index out of range: -4 with length 3",
+		},
+		{
+			input:       "var grid = [[0, 0], [0, 0]]; grid[1][0] = 1; grid;",
+			returnType:  object.ARRAY_OBJ,
+			returnValue: "[[0, 0], [1, 0]]",
+		},
+		{
+			input:       `var h = {"a": 1}; h["a"] = 2; h["b"] = 3; h;`,
+			returnType:  object.HASH_OBJ,
+			returnValue: "{a: 2, b: 3}",
+		},
+		{
+			input:       `var h = {"xs": [1, 2]}; h["xs"][0] = "one"; h;`,
+			returnType:  object.HASH_OBJ,
+			returnValue: "{xs: [one, 2]}",
+		},
+		{
+			input:       `var s = "abc"; s[0] = 1;`,
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: index assignment must be applied to ARRAY, HASH or BYTES object",
+		},
+	}
+	for i, tC := range testCases {
+		eval := evaluate(t, i, tC.input)
+		checkTypeAndValue(t, i, eval, tC.returnType, tC.returnValue)
+	}
+}
+
+func TestIfEvaluation(t *testing.T) {
+	testCases := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{
+			input:       "var a = 3; var b = 5; var x = if a > b { a } else { b }; x;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "5",
+		},
+		{
+			input:       "fun sign(n) { return if n < 0 { -1 } else if n == 0 { 0 } else { 1 }; } sign(-4);",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "-1",
+		},
+		{
+			input:       "fun sign(n) { if n < 0 { return -1; } else if n == 0 { return 0; } else { return 1; } } sign(0);",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "0",
+		},
+		{
+			input:       "fun sign(n) { if n < 0 { return -1; } else if n == 0 { return 0; } else { return 1; } } sign(7);",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "1",
+		},
+		{
+			input:       "if false { 1 }",
+			returnType:  object.NULL_OBJ,
+			returnValue: "null",
+		},
+		{
+			input:       "var x = if 1 > 2 { 1 }; x;",
+			returnType:  object.NULL_OBJ,
+			returnValue: "null",
+		},
+	}
+	for i, tC := range testCases {
+		eval := evaluate(t, i, tC.input)
+		checkTypeAndValue(t, i, eval, tC.returnType, tC.returnValue)
+	}
+}
+
+func TestForEvaluation(t *testing.T) {
+	testCases := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{
+			input:       "fun f() { for var i = 0; i < 10; i = i + 1 { if i == 5 { return i; } } return 0; } f();",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "5",
+		},
+		{
+			input:       "for var i = 0; i < 3; i = i + 1 { i * 2; }",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "4",
+		},
+		{
+			input:       "fun f(xs) { for x in xs { if x > 2 { return x; } } return 0; } f([1, 2, 3, 4]);",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "3",
+		},
+		{
+			input:       "var x = 7; for x in [1, 2] { x; } x;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "7",
+		},
+		{
+			input:       "for x in 5 { x; }",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot iterate over INTEGER",
+		},
+	}
+	for i, tC := range testCases {
+		eval := evaluate(t, i, tC.input)
+		checkTypeAndValue(t, i, eval, tC.returnType, tC.returnValue)
+	}
+}
+
+func TestLoopControlEvaluation(t *testing.T) {
+	testCases := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{
+			input:       "var n = 0; while true { n = n + 1; if n == 3 { break; } } n;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "3",
+		},
+		{
+			input:       "var n = 0; while (1 / 0) { n = 1; } n;",
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: division by zero",
+		},
+		{
+			input:       "var n = 0; var s = 0; while n < 5 { n = n + 1; if n == 2 { continue; } s = s + n; } s;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "13",
+		},
+		{
+			input:       "fun f() { for x in [1, 2, 3] { if x == 2 { break; } } return 1; } f();",
 			returnType:  object.INTEGER_OBJ,
 			returnValue: "1",
 		},
+		{
+			input:       "outer: while true { while true { break outer; } return 1; } 2;",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "2",
+		},
+		{
+			input:       "fun f() { var i = 0; outer: while i < 3 { i = i + 1; while true { continue outer; } } return i; } f();",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "3",
+		},
+		{
+			input:       "fun f() { outer: for var i = 0; i < 5; i = i + 1 { for x in [1, 2] { if i < 3 { continue outer; } return i; } } return 0; } f();",
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "3",
+		},
 	}
 	for i, tC := range testCases {
 		eval := evaluate(t, i, tC.input)
@@ -142,6 +1076,343 @@ func TestFunctionEvaluation(t *testing.T) {
 	}
 }
 
+func TestTryEvaluation(t *testing.T) {
+	tests := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{`var r = 0; try { r = 1 / 0; } catch (e) { r = 2; } r;`, object.INTEGER_OBJ, "2"},
+		{`var r = 0; try { r = 1; } catch (e) { r = 2; } r;`, object.INTEGER_OBJ, "1"},
+		{`var m; try { 1 / 0; } catch (e) { m = e["message"]; } m;`, object.STRING_OBJ, "division by zero"},
+		{`var k; try { 1 / 0; } catch (e) { k = e["kind"]; } k;`, object.STRING_OBJ, "runtime"},
+		{`var k; try { int x = "a"; } catch (e) { k = e["kind"]; } k;`, object.STRING_OBJ, "type"},
+		{`var k; try { read(1); } catch (e) { k = e["kind"]; } k;`, object.STRING_OBJ, "runtime"},
+		{`var k; try { throw "boom"; } catch (e) { k = e["kind"] + ": " + e["message"]; } k;`, object.STRING_OBJ, "user: boom"},
+		{`var v; try { throw [1, 2]; } catch (e) { v = e["value"][1]; } v;`, object.INTEGER_OBJ, "2"},
+		{`var v; try { 1 / 0; } catch (e) { v = e["value"]; } v;`, object.NULL_OBJ, "null"},
+		{"var p;\ntry {\n\tx;\n} catch (e) {\n\tp = [e[\"line\"], e[\"column\"]];\n}\np;", object.ARRAY_OBJ, "[3, 2]"},
+		{`var e; try { throw 1; } catch (err) { e = err; } e;`, object.ERROR_VALUE_OBJ, "error: 1"},
+		{`var e; try { throw 1; } catch (err) { e = err; } e["size"];`, object.ERROR_OBJ, "ERROR: unknown error field size"},
+		{`fun f() { throw "inner"; } var s; try { f(); } catch (e) { s = e["stack"]; } len(s);`, object.INTEGER_OBJ, "1"},
+		{`var r = 0; try { r = r * 10 + 1; } finally { r = r * 10 + 2; } r;`, object.INTEGER_OBJ, "12"},
+		{`var r = 0; try { 1 / 0; } catch (e) { r = r * 10 + 1; } finally { r = r * 10 + 2; } r;`, object.INTEGER_OBJ, "12"},
+		{`var r = 0; try { 1 / 0; } finally { r = 1; }`, object.ERROR_OBJ, "ERROR: division by zero"},
+		{`fun f() { try { return 1; } finally { print(); } } f();`, object.INTEGER_OBJ, "1"},
+		{`fun f() { try { return 1; } finally { return 2; } } f();`, object.INTEGER_OBJ, "2"},
+		{`var i = 0; while true { try { i++; if i == 3 { break; } } finally { } } i;`, object.INTEGER_OBJ, "3"},
+		{`try { 1 / 0; } catch (e) { throw e; }`, object.ERROR_OBJ, "ERROR: division by zero"},
+		{`try { throw "a"; } catch (e) { throw "b"; }`, object.ERROR_OBJ, "ERROR: b"},
+		{`try { try { throw "a"; } finally { } } catch (e) { e["message"]; }`, object.STRING_OBJ, "a"},
+		{`throw "uncaught";`, object.ERROR_OBJ, "ERROR: uncaught"},
+	}
+	for i, tt := range tests {
+		checkTypeAndValue(t, i, evaluate(t, i, tt.input), tt.returnType, tt.returnValue)
+	}
+}
+
+func TestErrorValueEvaluation(t *testing.T) {
+	tests := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{`error("bad");`, object.ERROR_VALUE_OBJ, "error: bad"},
+		{`error("bad")["kind"];`, object.STRING_OBJ, "user"},
+		{`is_error(error("bad"));`, object.BOOLEAN_OBJ, "true"},
+		{`is_error(1);`, object.BOOLEAN_OBJ, "false"},
+		{`is_error("error");`, object.BOOLEAN_OBJ, "false"},
+		{`error(1);`, object.ERROR_OBJ, "ERROR: error message must be a string"},
+		{`is_error();`, object.ERROR_OBJ, "ERROR: is_error function only accepts one parameter"},
+		{`var r = read("/nonexistent/file"); is_error(r);`, object.BOOLEAN_OBJ, "true"},
+		{`read("/nonexistent/file")["kind"];`, object.STRING_OBJ, "io"},
+		{"var a = 1;\nvar e = error(\"x\");\n[e[\"line\"], e[\"column\"]];", object.ARRAY_OBJ, "[2, 14]"},
+		{`try { throw error("bad"); } catch (e) { e["kind"] + ": " + e["message"]; }`, object.STRING_OBJ, "user: bad"},
+	}
+	for i, tt := range tests {
+		checkTypeAndValue(t, i, evaluate(t, i, tt.input), tt.returnType, tt.returnValue)
+	}
+}
+
+func TestPropagateEvaluation(t *testing.T) {
+	tests := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{`fun f() { return 1? + 1; } f();`, object.INTEGER_OBJ, "2"},
+		{`fun f() { var x = error("bad")?; return 1; } f();`, object.ERROR_VALUE_OBJ, "error: bad"},
+		{`fun check(x) { if x < 0 { return error("negative"); } return x; } fun twice(x) { return check(x)? * 2; } [twice(2), twice(-1)];`, object.ARRAY_OBJ, "[4, error: negative]"},
+		{`fun f() { for var i = 0; i < 10; i++ { if i == 3 { error("stop")?; } } return 0; } f();`, object.ERROR_VALUE_OBJ, "error: stop"},
+		{`fun f() int { error("typed")?; return 1; } f();`, object.ERROR_VALUE_OBJ, "error: typed"},
+		{`fun f() int { return error("typed"); } f();`, object.ERROR_VALUE_OBJ, "error: typed"},
+		{`fun f() { try { error("not caught")?; } catch (e) { return 1; } return 2; } f();`, object.ERROR_VALUE_OBJ, "error: not caught"},
+		{`var r = 0; fun f() { try { error("x")?; } finally { r = 1; } } f(); r;`, object.INTEGER_OBJ, "1"},
+		{`fun inner() { return error("deep")?; } fun outer() { inner()?; return "ok"; } outer();`, object.ERROR_VALUE_OBJ, "error: deep"},
+		{`error("top")?; 1;`, object.ERROR_VALUE_OBJ, "error: top"},
+	}
+	for i, tt := range tests {
+		checkTypeAndValue(t, i, evaluate(t, i, tt.input), tt.returnType, tt.returnValue)
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		position string
+	}{
+		{"var a = 1;\nb;", "2:1"},
+		{"var a = 1;\nvar b = a / 0;", "2:11"},
+		{"fun f(x) {\n\treturn x + y;\n}\nf(1);", "2:13"},
+		{"int n = 1;\nn = \"s\";", "2:1"},
+	}
+	for i, tt := range tests {
+		err, ok := evaluate(t, i, tt.input).(*object.Error)
+		if !ok {
+			t.Fatalf("tests[%d]: expected error for %q", i, tt.input)
+		}
+		if err.Token.Position() != tt.position {
+			t.Errorf("tests[%d]: wrong position. expected=%q, got=%q", i, tt.position, err.Token.Position())
+		}
+	}
+}
+
+func TestCallStack(t *testing.T) {
+	input := `fun inner(x) {
+	return x / 0;
+}
+fun outer(x) {
+	return inner(x);
+}
+outer(1);`
+	err, ok := evaluate(t, 0, input).(*object.Error)
+	if !ok {
+		t.Fatalf("expected error")
+	}
+	expected := []string{"inner (5:14)", "outer (7:6)"}
+	if len(err.Stack) != len(expected) {
+		t.Fatalf("wrong stack length. expected=%d, got=%d", len(expected), len(err.Stack))
+	}
+	for i, frame := range err.Stack {
+		if frame.String() != expected[i] {
+			t.Errorf("wrong frame %d. expected=%q, got=%q", i, expected[i], frame.String())
+		}
+	}
+}
+
+func TestTraceEvaluation(t *testing.T) {
+	tests := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{"trace();", object.ARRAY_OBJ, "[]"},
+		{"fun f() { return trace(); } f();", object.ARRAY_OBJ, "[f (1:30)]"},
+		{"fun f() { return trace(); } fun g() { return f(); } g();", object.ARRAY_OBJ, "[f (1:47), g (1:54)]"},
+		{"var f = fun() { return trace(); }; f();", object.ARRAY_OBJ, "[<fun> (1:37)]"},
+		{"fun f(g) { return g(); } f(fun() { return len(trace()); });", object.INTEGER_OBJ, "2"},
+		{"trace(1);", object.ERROR_OBJ, "ERROR: trace function does not accept parameters"},
+	}
+	for i, tt := range tests {
+		checkTypeAndValue(t, i, evaluate(t, i, tt.input), tt.returnType, tt.returnValue)
+	}
+}
+
+func TestImportEvaluation(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"util.mst":    `var greeting = "hi"; fun add(a, b) { return a + b; }`,
+		"counter.mst": `var count = 0; fun bump() { count += 1; return count; }`,
+		"sum.mst":     `import "util.mst" as util; var n = util["add"](2, 3);`,
+		"a.mst":       `import "b.mst" as b;`,
+		"b.mst":       `import "a.mst" as a;`,
+		"bad.mst":     `var = 1;`,
+		"fail.mst":    `var x = 1 / 0;`,
+	}
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	imp := func(name, as string) string {
+		return `import "` + filepath.Join(dir, name) + `" as ` + as + `; `
+	}
+	tests := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{imp("util.mst", "u") + `u["add"](1, 2);`, object.INTEGER_OBJ, "3"},
+		{imp("util.mst", "u") + `u["greeting"];`, object.STRING_OBJ, "hi"},
+		{imp("util.mst", "u") + `u.add(u.greeting, "!");`, object.STRING_OBJ, "hi!"},
+		{imp("util.mst", "u") + `u;`, object.NAMESPACE_OBJ, "<namespace util.mst>"},
+		{imp("counter.mst", "a") + `a["bump"](); ` + imp("counter.mst", "b") + `b["bump"]();`, object.INTEGER_OBJ, "2"},
+		{imp("sum.mst", "s") + `s["n"];`, object.INTEGER_OBJ, "5"},
+		{imp("sum.mst", "s") + `s["util"]["greeting"];`, object.STRING_OBJ, "hi"},
+		{imp("util.mst", "u") + `u["missing"];`, object.ERROR_OBJ, "ERROR: namespace util.mst has no member missing"},
+		{imp("util.mst", "u") + `u[1];`, object.ERROR_OBJ, "ERROR: namespace member must be a STRING, got INTEGER"},
+		{imp("a.mst", "a"), object.ERROR_OBJ, "ERROR: import cycle: a.mst -> b.mst -> a.mst"},
+		{imp("missing.mst", "m"), object.ERROR_OBJ, "ERROR: could not import file: open " + filepath.Join(dir, "missing.mst") + ": no such file or directory"},
+		{imp("bad.mst", "b"), object.ERROR_OBJ, "ERROR: expected identifier"},
+		{imp("fail.mst", "f"), object.ERROR_OBJ, "ERROR: division by zero"},
+		{`try { ` + imp("fail.mst", "f") + `} catch (e) { e["file"]; }`, object.STRING_OBJ, filepath.Join(dir, "fail.mst")},
+	}
+	for i, tt := range tests {
+		checkTypeAndValue(t, i, evaluate(t, i, tt.input), tt.returnType, tt.returnValue)
+	}
+}
+
+func TestLoadEvaluation(t *testing.T) {
+	tests := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{`var math = load("math"); math["sqrt"](16);`, object.FLOAT_OBJ, "4.000000"},
+		{`var math = load("math"); math["pi"];`, object.FLOAT_OBJ, "3.141593"},
+		{`load("math");`, object.NAMESPACE_OBJ, "<namespace math>"},
+		{`var math = load("math"); math["floor"](2.7);`, object.INTEGER_OBJ, "2"},
+		{`var math = load("math"); math["round"](-2.5);`, object.INTEGER_OBJ, "-3"},
+		{`var math = load("math"); math["abs"](-3);`, object.INTEGER_OBJ, "3"},
+		{`var math = load("math"); math["abs"](-1.5);`, object.FLOAT_OBJ, "1.500000"},
+		{`var math = load("math"); math["pow"](2, 10);`, object.FLOAT_OBJ, "1024.000000"},
+		{`var math = load("math"); math["max"](1, 3.5, 2);`, object.FLOAT_OBJ, "3.500000"},
+		{`var math = load("math"); math["min"](4, 2, 3);`, object.INTEGER_OBJ, "2"},
+		{`var math = load("math"); math["sqrt"]("x");`, object.ERROR_OBJ, "ERROR: sqrt argument must be a number"},
+		{`var math = load("math"); math["min"]();`, object.ERROR_OBJ, "ERROR: min function expects at least one parameter"},
+		{`var math = load("math"); math["tau"];`, object.ERROR_OBJ, "ERROR: namespace math has no member tau"},
+		{`sqrt(4);`, object.ERROR_OBJ, "ERROR: identifier not found: sqrt"},
+		{`load("prelude")["len"]("abc");`, object.INTEGER_OBJ, "3"},
+		{`load("nope");`, object.ERROR_OBJ, "ERROR: unknown library: nope"},
+		{`load(1);`, object.ERROR_OBJ, "ERROR: load argument must be a string"},
+	}
+	for i, tt := range tests {
+		checkTypeAndValue(t, i, evaluate(t, i, tt.input), tt.returnType, tt.returnValue)
+	}
+}
+
+func TestMemberEvaluation(t *testing.T) {
+	tests := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{`"abc".upper();`, object.STRING_OBJ, "ABC"},
+		{`var s = "  Hi There "; s.trim().lower();`, object.STRING_OBJ, "hi there"},
+		{`"a,b,c".split(",");`, object.ARRAY_OBJ, "[a, b, c]"},
+		{`"hello".contains("ell");`, object.BOOLEAN_OBJ, "true"},
+		{`"hello".starts_with("he") and "hello".ends_with("lo");`, object.BOOLEAN_OBJ, "true"},
+		{`"hello".index("l");`, object.INTEGER_OBJ, "2"},
+		{`"a-b".replace("-", "+");`, object.STRING_OBJ, "a+b"},
+		{`"abc".len();`, object.INTEGER_OBJ, "3"},
+		{`var arr = [1]; arr.push(2, 3); arr;`, object.ARRAY_OBJ, "[1, 2, 3]"},
+		{`var arr = [1, 2]; var last = arr.pop(); [last, arr];`, object.ARRAY_OBJ, "[2, [1]]"},
+		{`[].pop();`, object.ERROR_OBJ, "ERROR: pop from empty array"},
+		{`var arr = [1]; [arr.push(2), arr];`, object.ARRAY_OBJ, "[null, [1, 2]]"},
+		{`try { 1 / 0; } catch (e) { e.kind + ": " + e.message; }`, object.STRING_OBJ, "runtime: division by zero"},
+		{`try { 1 / 0; } catch (e) { e.value; }`, object.NULL_OBJ, "null"},
+		{`try { throw 5; } catch (e) { e.value; }`, object.INTEGER_OBJ, "5"},
+		{`error("x").missing;`, object.ERROR_OBJ, "ERROR: ERROR_VALUE has no member missing"},
+		{`[1, "a", 2].index("a");`, object.INTEGER_OBJ, "1"},
+		{`[1, 2].contains(3);`, object.BOOLEAN_OBJ, "false"},
+		{`["a", 1, true].join("-");`, object.STRING_OBJ, "a-1-true"},
+		{`var h = {"a": 1}; h.has("a");`, object.BOOLEAN_OBJ, "true"},
+		{`{"a": 1, "b": 2}.keys();`, object.ARRAY_OBJ, "[a, b]"},
+		{`var m = load("math"); m.floor(m.pi);`, object.INTEGER_OBJ, "3"},
+		{`var up = "abc".upper; up();`, object.STRING_OBJ, "ABC"},
+		{`"abc".upper(1);`, object.ERROR_OBJ, "ERROR: upper method does not accept parameters"},
+		{`"abc".split(1);`, object.ERROR_OBJ, "ERROR: split argument must be a string"},
+		{`"abc".replace("a");`, object.ERROR_OBJ, "ERROR: replace method only accepts 2 parameters"},
+		{`"abc".missing;`, object.ERROR_OBJ, "ERROR: STRING has no member missing"},
+		{`var x = 1; x.y;`, object.ERROR_OBJ, "ERROR: INTEGER has no member y"},
+		{`load("math").tau;`, object.ERROR_OBJ, "ERROR: NAMESPACE has no member tau"},
+	}
+	for i, tt := range tests {
+		checkTypeAndValue(t, i, evaluate(t, i, tt.input), tt.returnType, tt.returnValue)
+	}
+}
+
+func TestStructEvaluation(t *testing.T) {
+	point := `struct Point { x, y } `
+	typed := `struct Vec { int x, float y = 0 } `
+	tests := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{point + `Point(1, 2);`, object.STRUCT_OBJ, "Point{x: 1, y: 2}"},
+		{point + `Point(y = 2, x = "a");`, object.STRUCT_OBJ, "Point{x: a, y: 2}"},
+		{point + `var p = Point(1, 2); p.x + p.y;`, object.INTEGER_OBJ, "3"},
+		{point + `var p = Point(1, 2); p.x = 5; p.y += 1; p.x++; p;`, object.STRUCT_OBJ, "Point{x: 6, y: 3}"},
+		{point + `Point(1, [2]) == Point(1, [2]);`, object.BOOLEAN_OBJ, "false"},
+		{point + `Point(1, "a") == Point(1, "a");`, object.BOOLEAN_OBJ, "true"},
+		{point + `Point(1, 2) != Point(1, 3);`, object.BOOLEAN_OBJ, "true"},
+		{point + `struct Other { x, y } Point(1, 2) == Other(1, 2);`, object.BOOLEAN_OBJ, "false"},
+		{point + `Point(Point(0, 0), 1) == Point(Point(0, 0), 1);`, object.BOOLEAN_OBJ, "true"},
+		{point + `Point;`, object.STRUCT_TYPE_OBJ, "<struct Point>"},
+		{point + `Point(1);`, object.ERROR_OBJ, "ERROR: function Point expects 2 arguments, got 1"},
+		{point + `Point(1, z = 2);`, object.ERROR_OBJ, "ERROR: function Point has no parameter named z"},
+		{point + `Point(1, 2).z;`, object.ERROR_OBJ, "ERROR: struct Point has no member z"},
+		{point + `var p = Point(1, 2); p.z = 1;`, object.ERROR_OBJ, "ERROR: struct Point has no field z"},
+		{`var s = "a"; s.x = 1;`, object.ERROR_OBJ, "ERROR: cannot assign to member x of STRING"},
+		{typed + `Vec(1);`, object.STRUCT_OBJ, "Vec{x: 1, y: 0.000000}"},
+		{typed + `Vec(1, 2).y;`, object.FLOAT_OBJ, "2.000000"},
+		{typed + `Vec("a");`, object.ERROR_OBJ, "ERROR: cannot pass STRING as parameter x of type INTEGER"},
+		{typed + `var v = Vec(1); v.y = 3; v.y;`, object.FLOAT_OBJ, "3.000000"},
+		{typed + `var v = Vec(1); v.x = 1.5;`, object.ERROR_OBJ, "ERROR: cannot assign FLOAT to field x of type INTEGER"},
+		{`struct INTEGER { x } INTEGER(1) + 1;`, object.ERROR_OBJ, "ERROR: type mismatch"},
+		{`struct INTEGER { x } int x = 0; x = INTEGER(5);`, object.ERROR_OBJ, "ERROR: cannot assign struct INTEGER to x of type INTEGER"},
+		{`struct ARRAY { x } ARRAY(1).push(2);`, object.ERROR_OBJ, "ERROR: struct ARRAY has no member push"},
+		{`fun make() { struct Local { v } return Local(1); } make().v;`, object.INTEGER_OBJ, "1"},
+	}
+	for i, tt := range tests {
+		checkTypeAndValue(t, i, evaluate(t, i, tt.input), tt.returnType, tt.returnValue)
+	}
+}
+
+func TestClassEvaluation(t *testing.T) {
+	stack := `class Stack {
+		fun init(self, items = []) { self.items = items; }
+		fun push(self, v) { self.items.push(v); return self; }
+		fun pop(self) { return self.items.pop(); }
+		fun size(self) { return len(self.items); }
+	} `
+	counted := stack + `class Counted(Stack) {
+		fun init(self) { super.init(); self.pushes = 0; }
+		fun push(self, v) { self.pushes += 1; return super.push(v); }
+	} `
+	tests := []struct {
+		input       string
+		returnType  object.ObjectType
+		returnValue string
+	}{
+		{stack + `var s = Stack(); s.push(1); s.push(2); s.pop();`, object.INTEGER_OBJ, "2"},
+		{stack + `Stack([1]).push(2).push(3).size();`, object.INTEGER_OBJ, "3"},
+		{stack + `Stack(items = [1]);`, object.INSTANCE_OBJ, "Stack{items: [1]}"},
+		{stack + `var a = Stack(); var b = Stack(); a.push(1); b.size();`, object.INTEGER_OBJ, "0"},
+		{stack + `var push = Stack().push; push(1).items;`, object.ARRAY_OBJ, "[1]"},
+		{stack + `Stack;`, object.CLASS_OBJ, "<class Stack>"},
+		{stack + `Stack().push();`, object.ERROR_OBJ, "ERROR: function Stack.push expects 1 argument, got 0"},
+		{stack + `Stack(1, 2);`, object.ERROR_OBJ, "ERROR: function Stack.init expects at most 1 argument, got 2"},
+		{stack + `Stack().missing;`, object.ERROR_OBJ, "ERROR: instance of Stack has no member missing"},
+		{counted + `var c = Counted(); c.push(1); c.push(2); [c.pushes, c.size()];`, object.ARRAY_OBJ, "[2, 2]"},
+		{counted + `Counted().push(5);`, object.INSTANCE_OBJ, "Counted{items: [5], pushes: 1}"},
+		{counted + `class Top(Counted) { fun size(self) { return super.size() * 10; } } var t = Top(); t.push(1); t.size();`, object.INTEGER_OBJ, "10"},
+		{`class Point { } var p = Point(); p.x = 1; p.x += 1; p.x;`, object.INTEGER_OBJ, "2"},
+		{`class Point { } Point(1);`, object.ERROR_OBJ, "ERROR: class Point has no init method and takes no arguments, got 1"},
+		{`class A { fun f(self) { return super.f(); } } A().f();`, object.ERROR_OBJ, "ERROR: super can only be used in the methods of a class that inherits"},
+		{`var A = 1; class B(A) { }`, object.ERROR_OBJ, "ERROR: class B can not inherit from INTEGER"},
+		{`class A { fun who(self) { return "A"; } fun hello(self) { return "I am " + self.who(); } } class B(A) { fun who(self) { return "B"; } } B().hello();`, object.STRING_OBJ, "I am B"},
+		{`class A { fun f(self) { return 1 / 0; } } A().f();`, object.ERROR_OBJ, "ERROR: division by zero"},
+		{`class ERROR { } var e = ERROR(); e;`, object.INSTANCE_OBJ, "ERROR{}"},
+		{`class ARRAY { } ARRAY().push(1);`, object.ERROR_OBJ, "ERROR: instance of ARRAY has no member push"},
+		{`class INTEGER { } INTEGER() + 1;`, object.ERROR_OBJ, "ERROR: type mismatch"},
+	}
+	for i, tt := range tests {
+		checkTypeAndValue(t, i, evaluate(t, i, tt.input), tt.returnType, tt.returnValue)
+	}
+}
+
 func evaluate(t *testing.T, testNum int, input string) object.Object {
 	l := lexer.New(input)
 	if l.HasError {
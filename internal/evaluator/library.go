@@ -0,0 +1,26 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/internal/object"
+)
+
+// libraries are the native modules scripts can load by name.
+var libraries = map[string]*object.NativeModule{}
+
+// prelude is the native module every scope sees without loading it.
+var prelude = &object.NativeModule{Name: "prelude", Functions: stdFunc}
+
+func init() {
+	Register(prelude)
+}
+
+// Register makes module available to load. It is meant to be called from the
+// init function of the package implementing the module, and panics if a
+// module with the same name is already registered.
+func Register(module *object.NativeModule) {
+	if _, ok := libraries[module.Name]; ok {
+		panic(fmt.Sprintf("library %s registered twice", module.Name))
+	}
+	libraries[module.Name] = module
+}
@@ -0,0 +1,176 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/internal/object"
+	"strings"
+)
+
+// method is a builtin method, called with the object it was looked up on.
+type method func(receiver object.Object, params ...object.Object) object.Object
+
+// methods are the builtin methods of each type, as in "abc".upper().
+var methods = map[object.ObjectType]map[string]method{
+	object.STRING_OBJ: {
+		"len":   stdMethod("len"),
+		"upper": stringMethod("upper", 0, func(s string, _ []string) object.Object { return &object.String{Value: strings.ToUpper(s)} }),
+		"lower": stringMethod("lower", 0, func(s string, _ []string) object.Object { return &object.String{Value: strings.ToLower(s)} }),
+		"trim":  stringMethod("trim", 0, func(s string, _ []string) object.Object { return &object.String{Value: strings.TrimSpace(s)} }),
+		"contains": stringMethod("contains", 1, func(s string, args []string) object.Object {
+			return nativeBoolToBooleanObject(strings.Contains(s, args[0]))
+		}),
+		"starts_with": stringMethod("starts_with", 1, func(s string, args []string) object.Object {
+			return nativeBoolToBooleanObject(strings.HasPrefix(s, args[0]))
+		}),
+		"ends_with": stringMethod("ends_with", 1, func(s string, args []string) object.Object {
+			return nativeBoolToBooleanObject(strings.HasSuffix(s, args[0]))
+		}),
+		"index": stringMethod("index", 1, func(s string, args []string) object.Object {
+			return &object.Integer{Value: int64(strings.Index(s, args[0]))}
+		}),
+		"replace": stringMethod("replace", 2, func(s string, args []string) object.Object {
+			return &object.String{Value: strings.ReplaceAll(s, args[0], args[1])}
+		}),
+		"split": stringMethod("split", 1, func(s string, args []string) object.Object {
+			parts := []object.Object{}
+			for _, part := range strings.Split(s, args[0]) {
+				parts = append(parts, &object.String{Value: part})
+			}
+			return &object.Array{Elements: parts}
+		}),
+	},
+	object.ARRAY_OBJ: {
+		"len":      stdMethod("len"),
+		"push":     arrayPush,
+		"pop":      arrayPop,
+		"contains": arrayContains,
+		"index":    arrayIndexOf,
+		"join":     arrayJoin,
+	},
+	object.HASH_OBJ: {
+		"len":    stdMethod("len"),
+		"keys":   stdMethod("keys"),
+		"values": stdMethod("values"),
+		"has":    stdMethod("has"),
+		"delete": stdMethod("delete"),
+	},
+}
+
+// evalMemberExpression looks up the member called name of obj, which is
+// either an attribute of obj or a builtin method bound to it.
+func evalMemberExpression(obj object.Object, name string) object.Object {
+	if attributable, ok := obj.(object.Attributable); ok {
+		if member, ok := attributable.Attribute(name); ok {
+			if member == nil {
+				return NULL
+			}
+			return member
+		}
+	}
+	if m, ok := methods[typeOf(obj)][name]; ok {
+		return &object.StdFunction{
+			Fun: func(params ...object.Object) object.Object {
+				return m(obj, params...)
+			},
+		}
+	}
+	return &object.Error{Error: fmt.Sprintf("%s has no member %s", typeName(obj), name)}
+}
+
+// stdMethod calls the prelude function called name with the receiver as its
+// first argument, so h.keys() is keys(h).
+func stdMethod(name string) method {
+	return func(receiver object.Object, params ...object.Object) object.Object {
+		return stdFunc[name].Fun(append([]object.Object{receiver}, params...)...)
+	}
+}
+
+func methodArity(name string, params []object.Object, n int) *object.Error {
+	if len(params) == n {
+		return nil
+	}
+	switch n {
+	case 0:
+		return &object.Error{Error: fmt.Sprintf("%s method does not accept parameters", name)}
+	case 1:
+		return &object.Error{Error: fmt.Sprintf("%s method only accepts one parameter", name)}
+	default:
+		return &object.Error{Error: fmt.Sprintf("%s method only accepts %d parameters", name, n)}
+	}
+}
+
+// stringMethod wraps f as a method of strings taking n string arguments.
+func stringMethod(name string, n int, f func(s string, args []string) object.Object) method {
+	return func(receiver object.Object, params ...object.Object) object.Object {
+		if err := methodArity(name, params, n); err != nil {
+			return err
+		}
+		args := make([]string, len(params))
+		for i, param := range params {
+			str, ok := param.(*object.String)
+			if !ok {
+				return &object.Error{Error: fmt.Sprintf("%s argument must be a string", name)}
+			}
+			args[i] = str.Value
+		}
+		return f(receiver.(*object.String).Value, args)
+	}
+}
+
+func arrayPush(receiver object.Object, params ...object.Object) object.Object {
+	arr := receiver.(*object.Array)
+	arr.Elements = append(arr.Elements, params...)
+	return NULL
+}
+
+func arrayPop(receiver object.Object, params ...object.Object) object.Object {
+	if err := methodArity("pop", params, 0); err != nil {
+		return err
+	}
+	arr := receiver.(*object.Array)
+	if len(arr.Elements) == 0 {
+		return &object.Error{Error: "pop from empty array"}
+	}
+	last := arr.Elements[len(arr.Elements)-1]
+	arr.Elements = arr.Elements[:len(arr.Elements)-1]
+	return last
+}
+
+func arrayIndexOf(receiver object.Object, params ...object.Object) object.Object {
+	if err := methodArity("index", params, 1); err != nil {
+		return err
+	}
+	for i, element := range receiver.(*object.Array).Elements {
+		if evalInfixExpression(element, params[0], "==") == TRUE {
+			return &object.Integer{Value: int64(i)}
+		}
+	}
+	return &object.Integer{Value: -1}
+}
+
+func arrayContains(receiver object.Object, params ...object.Object) object.Object {
+	if err := methodArity("contains", params, 1); err != nil {
+		return err
+	}
+	index := arrayIndexOf(receiver, params...).(*object.Integer)
+	return nativeBoolToBooleanObject(index.Value != -1)
+}
+
+func arrayJoin(receiver object.Object, params ...object.Object) object.Object {
+	if err := methodArity("join", params, 1); err != nil {
+		return err
+	}
+	sep, ok := params[0].(*object.String)
+	if !ok {
+		return &object.Error{Error: "join argument must be a string"}
+	}
+	parts := []string{}
+	for _, element := range receiver.(*object.Array).Elements {
+		if str, ok := element.(*object.String); ok {
+			parts = append(parts, str.Value)
+		} else {
+			parts = append(parts, element.Inspect())
+		}
+	}
+	return &object.String{Value: strings.Join(parts, sep.Value)}
+}
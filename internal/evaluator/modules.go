@@ -0,0 +1,107 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/internal/ast"
+	"interpreter/internal/lexer"
+	"interpreter/internal/object"
+	"interpreter/internal/parser"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// module is a source file loaded by an import statement.
+type module struct {
+	namespace *object.Namespace
+	loading   bool
+}
+
+// modules caches the imported files by their absolute path, so a file runs
+// once however many times it is imported.
+var modules = map[string]*module{}
+
+// importing is the chain of files currently being imported, innermost last.
+var importing []string
+
+// evalImportStatement runs the imported file, unless it ran before, and binds
+// the namespace of its top-level names. Relative paths are resolved against
+// the directory of the importing file.
+func evalImportStatement(node *ast.ImportStatement, env *object.Environment) object.Object {
+	path := node.Path.Value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(node.Token.Filename), path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return &object.Error{Error: "could not import file: " + err.Error(), Kind: object.IO_ERROR}
+	}
+	namespace := importModule(path)
+	if isError(namespace) {
+		return namespace
+	}
+	env.Set(node.Name.Value, namespace)
+	return nil
+}
+
+func importModule(path string) object.Object {
+	if m, ok := modules[path]; ok {
+		if m.loading {
+			return importCycleError(path)
+		}
+		return m.namespace
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return &object.Error{Error: "could not import file: " + err.Error(), Kind: object.IO_ERROR}
+	}
+	p := parser.New(lexer.NewFile(path, string(source)))
+	prog := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return &object.Error{Error: p.Errors()[0].Message, Token: p.Errors()[0].Token}
+	}
+
+	env := object.NewEnvironment()
+	m := &module{
+		namespace: &object.Namespace{Name: filepath.Base(path), Members: env.Bindings()},
+		loading:   true,
+	}
+	modules[path] = m
+	importing = append(importing, path)
+	defer func() { importing = importing[:len(importing)-1] }()
+
+	if ret, ok := Eval(prog, env).(*object.Error); ok {
+		// a file that failed is run again the next time it is imported
+		delete(modules, path)
+		return ret
+	}
+	m.loading = false
+	return m.namespace
+}
+
+func importCycleError(path string) *object.Error {
+	var cycle []string
+	for i, p := range importing {
+		if p == path {
+			for _, p := range importing[i:] {
+				cycle = append(cycle, filepath.Base(p))
+			}
+			break
+		}
+	}
+	cycle = append(cycle, filepath.Base(path))
+	return &object.Error{Error: "import cycle: " + strings.Join(cycle, " -> ")}
+}
+
+// namespaceMember returns the member of namespace named by index.
+func namespaceMember(namespace *object.Namespace, index object.Object) object.Object {
+	name, ok := index.(*object.String)
+	if !ok {
+		return &object.Error{Error: "namespace member must be a STRING, got " + string(typeOf(index))}
+	}
+	member, ok := namespace.Members[name.Value]
+	if !ok {
+		return &object.Error{Error: fmt.Sprintf("namespace %s has no member %s", namespace.Name, name.Value)}
+	}
+	return member
+}
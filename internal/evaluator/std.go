@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"interpreter/internal/object"
 	"os"
+	"strconv"
 )
 
+// stdFunc are the functions of the prelude, which every script can call
+// without loading a library.
 var stdFunc = map[string]*object.StdFunction{
 	"print": {
 		Fun: func(params ...object.Object) object.Object {
@@ -22,6 +25,9 @@ var stdFunc = map[string]*object.StdFunction{
 	},
 	"len": {
 		Fun: func(params ...object.Object) object.Object {
+			if len(params) != 1 {
+				return &object.Error{Error: "len function only accepts one parameter"}
+			}
 			switch params[0].Type() {
 			case object.ARRAY_OBJ:
 				arr := params[0].(*object.Array)
@@ -29,6 +35,12 @@ var stdFunc = map[string]*object.StdFunction{
 			case object.STRING_OBJ:
 				str := params[0].(*object.String)
 				return &object.Integer{Value: int64(len(str.Value))}
+			case object.HASH_OBJ:
+				hash := params[0].(*object.Hash)
+				return &object.Integer{Value: int64(len(hash.Pairs))}
+			case object.BYTES_OBJ:
+				buf := params[0].(*object.Bytes)
+				return &object.Integer{Value: int64(len(buf.Value))}
 			default:
 				return &object.Integer{Value: 0}
 			}
@@ -57,7 +69,7 @@ var stdFunc = map[string]*object.StdFunction{
 			}
 			f, err := os.ReadFile(filename.Value)
 			if err != nil {
-				return &object.Error{Error: "could not open file: " + err.Error()}
+				return ioError("could not open file: " + err.Error())
 			}
 			return &object.String{Value: string(f)}
 		},
@@ -71,22 +83,242 @@ var stdFunc = map[string]*object.StdFunction{
 			if !ok {
 				return &object.Error{Error: "filename must be a string"}
 			}
-			data := params[1].(*object.String)
-			if !ok {
-				return &object.Error{Error: "data must be string"}
+			var data []byte
+			switch param := params[1].(type) {
+			case *object.String:
+				data = []byte(param.Value)
+			case *object.Bytes:
+				data = param.Value
+			default:
+				return &object.Error{Error: "data must be string or bytes"}
 			}
 			f, err := os.Create(filename.Value)
 			if err != nil {
-				return &object.Error{Error: "could not open file: " + err.Error()}
+				return ioError("could not open file: " + err.Error())
 			}
 			defer f.Close()
 			w := bufio.NewWriter(f)
-			_, err = w.WriteString(data.Value)
+			_, err = w.Write(data)
 			if err != nil {
-				return &object.Error{Error: "could not write to file: " + err.Error()}
+				return ioError("could not write to file: " + err.Error())
 			}
 			w.Flush()
 			return nil
 		},
 	},
+	"keys": {
+		Fun: func(params ...object.Object) object.Object {
+			if len(params) != 1 {
+				return &object.Error{Error: "keys function only accepts one parameter"}
+			}
+			hash, ok := params[0].(*object.Hash)
+			if !ok {
+				return &object.Error{Error: "keys argument must be a hash"}
+			}
+			keys := []object.Object{}
+			for _, pair := range hash.Entries() {
+				keys = append(keys, pair.Key)
+			}
+			return &object.Array{Elements: keys}
+		},
+	},
+	"values": {
+		Fun: func(params ...object.Object) object.Object {
+			if len(params) != 1 {
+				return &object.Error{Error: "values function only accepts one parameter"}
+			}
+			hash, ok := params[0].(*object.Hash)
+			if !ok {
+				return &object.Error{Error: "values argument must be a hash"}
+			}
+			values := []object.Object{}
+			for _, pair := range hash.Entries() {
+				values = append(values, pair.Value)
+			}
+			return &object.Array{Elements: values}
+		},
+	},
+	"has": {
+		Fun: func(params ...object.Object) object.Object {
+			if len(params) != 2 {
+				return &object.Error{Error: "has function only accepts two parameters"}
+			}
+			hash, ok := params[0].(*object.Hash)
+			if !ok {
+				return &object.Error{Error: "has argument must be a hash"}
+			}
+			key, ok := params[1].(object.Hashable)
+			if !ok {
+				return &object.Error{Error: fmt.Sprintf("unusable as hash key: %s", typeOf(params[1]))}
+			}
+			_, found := hash.Get(key)
+			return nativeBoolToBooleanObject(found)
+		},
+	},
+	"delete": {
+		Fun: func(params ...object.Object) object.Object {
+			if len(params) != 2 {
+				return &object.Error{Error: "delete function only accepts two parameters"}
+			}
+			hash, ok := params[0].(*object.Hash)
+			if !ok {
+				return &object.Error{Error: "delete argument must be a hash"}
+			}
+			key, ok := params[1].(object.Hashable)
+			if !ok {
+				return &object.Error{Error: fmt.Sprintf("unusable as hash key: %s", typeOf(params[1]))}
+			}
+			if value, found := hash.Delete(key); found {
+				return value
+			}
+			return NULL
+		},
+	},
+	"byte": {
+		Fun: func(params ...object.Object) object.Object {
+			if len(params) != 1 {
+				return &object.Error{Error: "byte function only accepts one parameter"}
+			}
+			switch param := params[0].(type) {
+			case *object.Byte:
+				return param
+			case *object.Integer:
+				if b, ok := toByte(param); ok {
+					return &object.Byte{Value: b}
+				}
+				return &object.Error{Error: fmt.Sprintf("byte value out of range: %d", param.Value)}
+			case *object.String:
+				if len(param.Value) != 1 {
+					return &object.Error{Error: fmt.Sprintf("cannot convert string of length %d to byte", len(param.Value))}
+				}
+				return &object.Byte{Value: param.Value[0]}
+			default:
+				return &object.Error{Error: fmt.Sprintf("cannot convert %s to byte", param.Type())}
+			}
+		},
+	},
+	"int": {
+		Fun: func(params ...object.Object) object.Object {
+			if len(params) != 1 {
+				return &object.Error{Error: "int function only accepts one parameter"}
+			}
+			switch param := params[0].(type) {
+			case *object.Integer:
+				return param
+			case *object.Byte:
+				return &object.Integer{Value: int64(param.Value)}
+			case *object.Float:
+				return &object.Integer{Value: int64(param.Value)}
+			case *object.String:
+				value, err := strconv.ParseInt(param.Value, 0, 64)
+				if err != nil {
+					return &object.Error{Error: fmt.Sprintf("cannot convert %q to int", param.Value)}
+				}
+				return &object.Integer{Value: value}
+			default:
+				return &object.Error{Error: fmt.Sprintf("cannot convert %s to int", param.Type())}
+			}
+		},
+	},
+	"string": {
+		Fun: func(params ...object.Object) object.Object {
+			if len(params) != 1 {
+				return &object.Error{Error: "string function only accepts one parameter"}
+			}
+			switch param := params[0].(type) {
+			case *object.String:
+				return param
+			case *object.Byte:
+				return &object.String{Value: string([]byte{param.Value})}
+			case *object.Bytes:
+				return &object.String{Value: string(param.Value)}
+			default:
+				return &object.String{Value: param.Inspect()}
+			}
+		},
+	},
+	"bytes": {
+		Fun: func(params ...object.Object) object.Object {
+			if len(params) != 1 {
+				return &object.Error{Error: "bytes function only accepts one parameter"}
+			}
+			switch param := params[0].(type) {
+			case *object.String:
+				return &object.Bytes{Value: []byte(param.Value)}
+			case *object.Bytes:
+				return &object.Bytes{Value: bytes.Clone(param.Value)}
+			case *object.Integer:
+				if param.Value < 0 {
+					return &object.Error{Error: fmt.Sprintf("negative bytes length %d", param.Value)}
+				}
+				return &object.Bytes{Value: make([]byte, param.Value)}
+			case *object.Array:
+				buf := make([]byte, len(param.Elements))
+				for i, element := range param.Elements {
+					b, ok := toByte(element)
+					if !ok {
+						return &object.Error{Error: fmt.Sprintf("cannot store %s in BYTES", element.Inspect())}
+					}
+					buf[i] = b
+				}
+				return &object.Bytes{Value: buf}
+			default:
+				return &object.Error{Error: fmt.Sprintf("cannot convert %s to bytes", param.Type())}
+			}
+		},
+	},
+	"is_error": {
+		Fun: func(params ...object.Object) object.Object {
+			if len(params) != 1 {
+				return &object.Error{Error: "is_error function only accepts one parameter"}
+			}
+			return nativeBoolToBooleanObject(typeOf(params[0]) == object.ERROR_VALUE_OBJ)
+		},
+	},
+	"error": {
+		Fun: func(params ...object.Object) object.Object {
+			if len(params) != 1 {
+				return &object.Error{Error: "error function only accepts one parameter"}
+			}
+			message, ok := params[0].(*object.String)
+			if !ok {
+				return &object.Error{Error: "error message must be a string"}
+			}
+			return &object.ErrorValue{Err: &object.Error{Error: message.Value, Kind: object.USER_ERROR}}
+		},
+	},
+	"load": {
+		Fun: func(params ...object.Object) object.Object {
+			if len(params) != 1 {
+				return &object.Error{Error: "load function only accepts one parameter"}
+			}
+			name, ok := params[0].(*object.String)
+			if !ok {
+				return &object.Error{Error: "load argument must be a string"}
+			}
+			module, ok := libraries[name.Value]
+			if !ok {
+				return &object.Error{Error: fmt.Sprintf("unknown library: %s", name.Value)}
+			}
+			return module.Namespace()
+		},
+	},
+	"trace": {
+		EnvFun: func(env *object.Environment, params ...object.Object) object.Object {
+			if len(params) != 0 {
+				return &object.Error{Error: "trace function does not accept parameters"}
+			}
+			stack := []object.Object{}
+			for _, frame := range env.Frame().Stack() {
+				stack = append(stack, &object.String{Value: frame.String()})
+			}
+			return &object.Array{Elements: stack}
+		},
+	},
+}
+
+// ioError returns a failed file operation as an error value, which scripts
+// check with is_error or pass on with the ? operator.
+func ioError(message string) object.Object {
+	return &object.ErrorValue{Err: &object.Error{Error: message, Kind: object.IO_ERROR}}
 }
@@ -26,6 +26,111 @@ func TestStdFunctions(t *testing.T) {
 			returnType:  object.INTEGER_OBJ,
 			returnValue: "0",
 		},
+		{
+			input:       `len({"a": 1, "b": 2});`,
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "2",
+		},
+		{
+			input:       `keys({"b": 1, "a": 2});`,
+			returnType:  object.ARRAY_OBJ,
+			returnValue: "[b, a]",
+		},
+		{
+			input:       `values({"b": 1, "a": 2});`,
+			returnType:  object.ARRAY_OBJ,
+			returnValue: "[1, 2]",
+		},
+		{
+			input:       `has({"a": 1}, "a");`,
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "true",
+		},
+		{
+			input:       `has({"a": 1}, "b");`,
+			returnType:  object.BOOLEAN_OBJ,
+			returnValue: "false",
+		},
+		{
+			input:       `var h = {"a": 1, "b": 2, "c": 3}; delete(h, "b"); h;`,
+			returnType:  object.HASH_OBJ,
+			returnValue: "{a: 1, c: 3}",
+		},
+		{
+			input:       `delete({"a": 1}, "b");`,
+			returnType:  object.NULL_OBJ,
+			returnValue: "null",
+		},
+		{
+			input:       `byte(65);`,
+			returnType:  object.BYTE_OBJ,
+			returnValue: "65",
+		},
+		{
+			input:       `byte("A");`,
+			returnType:  object.BYTE_OBJ,
+			returnValue: "65",
+		},
+		{
+			input:       `int('A');`,
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "65",
+		},
+		{
+			input:       `int("0x10");`,
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "16",
+		},
+		{
+			input:       `int(2.9);`,
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "2",
+		},
+		{
+			input:       `string('A');`,
+			returnType:  object.STRING_OBJ,
+			returnValue: "A",
+		},
+		{
+			input:       `string(42);`,
+			returnType:  object.STRING_OBJ,
+			returnValue: "42",
+		},
+		{
+			input:       `string(bytes("hi"));`,
+			returnType:  object.STRING_OBJ,
+			returnValue: "hi",
+		},
+		{
+			input:       `bytes([104, 'i']);`,
+			returnType:  object.BYTES_OBJ,
+			returnValue: "b\"hi\"",
+		},
+		{
+			input:       `len(bytes("abc"));`,
+			returnType:  object.INTEGER_OBJ,
+			returnValue: "3",
+		},
+		{
+			input:       `byte(256);`,
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: byte value out of range: 256",
+		},
+		{
+			input:       `byte("ab");`,
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot convert string of length 2 to byte",
+		},
+		{
+			input:       `int("x");`,
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot convert \"x\" to int",
+		},
+		{
+			input:       `bytes([1, 300]);`,
+			returnType:  object.ERROR_OBJ,
+			returnValue: "ERROR: cannot store 300 in BYTES",
+		},
 	}
 	for i, tC := range testCases {
 		eval := evaluate(t, i, tC.input)
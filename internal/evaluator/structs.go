@@ -0,0 +1,82 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/internal/ast"
+	"interpreter/internal/object"
+)
+
+func evalStructStatement(node *ast.StructStatement, env *object.Environment) object.Object {
+	env.Set(node.Identifier.Value, &object.StructType{Name: node.Identifier.Value, Fields: node.Fields, Env: env})
+	return nil
+}
+
+// construct creates a value of the struct type st. The arguments are bound to
+// the fields like the arguments of a function call to its parameters.
+func construct(st *object.StructType, params []object.Object, named []namedArgument, frame *object.Frame) object.Object {
+	constructor := &object.Function{Name: st.Name, Params: st.Fields, Env: st.Env}
+	args, err := expandEnv(constructor, params, named, frame)
+	if err != nil {
+		return err
+	}
+	fields := object.NewEnvironment()
+	for _, field := range st.Fields {
+		value, _ := args.Get(field.Value)
+		fields.SetTyped(field.Value, value, args.DeclaredType(field.Value))
+	}
+	return &object.Struct{StructType: st, Fields: fields}
+}
+
+// evalMemberAssignment stores value in the field member of obj. Struct fields
+// keep the type they were declared with, instances get new fields when they
+// are assigned to.
+func evalMemberAssignment(obj object.Object, member *ast.IdentifierExpression, value object.Object) object.Object {
+	if instance, ok := obj.(*object.Instance); ok {
+		instance.Fields.Set(member.Value, value)
+		return nil
+	}
+	s, ok := obj.(*object.Struct)
+	if !ok {
+		return &object.Error{Error: fmt.Sprintf("cannot assign to member %s of %s", member.Value, typeName(obj))}
+	}
+	if !s.Fields.Declared(member.Value) {
+		return &object.Error{Error: fmt.Sprintf("struct %s has no field %s", s.StructType.Name, member.Value)}
+	}
+	if typ := s.Fields.DeclaredType(member.Value); typ != "" {
+		converted, ok := convertTo(value, typ)
+		if !ok {
+			return typeError(member.Token, "cannot assign %s to field %s of type %s", typeName(value), member.Value, typ)
+		}
+		value = converted
+	}
+	s.Fields.Assign(member.Value, value)
+	return nil
+}
+
+// typeName names the type of obj in error messages, which includes the name
+// of the struct or class for struct values and instances.
+func typeName(obj object.Object) string {
+	switch obj := obj.(type) {
+	case *object.Struct:
+		return "struct " + obj.StructType.Name
+	case *object.Instance:
+		return "instance of " + obj.Class.Name
+	}
+	return string(typeOf(obj))
+}
+
+// structsEqual reports whether two structs are of the same type and have
+// equal fields.
+func structsEqual(left, right *object.Struct) bool {
+	if left.StructType != right.StructType {
+		return false
+	}
+	for _, field := range left.StructType.Fields {
+		l, _ := left.Fields.Get(field.Value)
+		r, _ := right.Fields.Get(field.Value)
+		if evalInfixExpression(l, r, "==") != TRUE {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/internal/ast"
+	"interpreter/internal/object"
+	"interpreter/internal/token"
+)
+
+// declaredTypes maps the type keywords used in annotations to the type of the
+// values they accept.
+var declaredTypes = map[token.TokenType]object.ObjectType{
+	token.TOKEN_INT:    object.INTEGER_OBJ,
+	token.TOKEN_FLOAT:  object.FLOAT_OBJ,
+	token.TOKEN_STRING: object.STRING_OBJ,
+	token.TOKEN_BOOL:   object.BOOLEAN_OBJ,
+	token.TOKEN_BYTE:   object.BYTE_OBJ,
+}
+
+// zeroValue is the value of a typed variable declared without one.
+func zeroValue(typ object.ObjectType) object.Object {
+	switch typ {
+	case object.INTEGER_OBJ:
+		return &object.Integer{Value: 0}
+	case object.FLOAT_OBJ:
+		return &object.Float{Value: 0}
+	case object.STRING_OBJ:
+		return &object.String{Value: ""}
+	case object.BOOLEAN_OBJ:
+		return FALSE
+	case object.BYTE_OBJ:
+		return &object.Byte{Value: 0}
+	}
+	return NULL
+}
+
+// convertTo reports whether value can be stored in a variable of type typ and
+// returns the value to store, which is the value itself except for integers
+// widened to floats.
+func convertTo(value object.Object, typ object.ObjectType) (object.Object, bool) {
+	if typeOf(value) == typ {
+		return value, true
+	}
+	if integer, ok := value.(*object.Integer); ok && typ == object.FLOAT_OBJ {
+		return &object.Float{Value: float64(integer.Value)}, true
+	}
+	return nil, false
+}
+
+func typeError(tok token.Token, format string, a ...any) *object.Error {
+	return &object.Error{Error: fmt.Sprintf(format, a...), Kind: object.TYPE_ERROR, Token: tok}
+}
+
+func evalVarStatement(node *ast.VarStatement, env *object.Environment) object.Object {
+	name := node.Identifier.Value
+	if env.Declared(name) {
+		return &object.Error{Error: fmt.Sprintf("variable %s is already declared in this scope", name)}
+	}
+	value := Eval(node.Value, env)
+	if isError(value) {
+		return value
+	}
+	typ, typed := declaredTypes[node.Token.Type]
+	if !typed {
+		if value == nil {
+			value = NULL
+		}
+		env.Set(name, value)
+		return nil
+	}
+	if node.Value == nil {
+		value = zeroValue(typ)
+	}
+	converted, ok := convertTo(value, typ)
+	if !ok {
+		return typeError(node.Identifier.Token, "cannot assign %s to %s of type %s", typeName(value), name, typ)
+	}
+	env.SetTyped(name, converted, typ)
+	return nil
+}
+
+// assignVariable stores value in the variable ident, enforcing the type the
+// variable was declared with.
+func assignVariable(ident *ast.IdentifierExpression, value object.Object, env *object.Environment) object.Object {
+	if typ := env.DeclaredType(ident.Value); typ != "" {
+		converted, ok := convertTo(value, typ)
+		if !ok {
+			return typeError(ident.Token, "cannot assign %s to %s of type %s", typeName(value), ident.Value, typ)
+		}
+		value = converted
+	}
+	if !env.Assign(ident.Value, value) {
+		return &object.Error{Error: "assignment to undeclared variable " + ident.Value}
+	}
+	return nil
+}
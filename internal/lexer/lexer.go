@@ -2,17 +2,48 @@ package lexer
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"interpreter/internal/token"
+	"strconv"
+	"unicode/utf8"
 )
 
+// ErrorKind classifies the errors found by the lexer.
+type ErrorKind string
+
+const (
+	IllegalCharacter   ErrorKind = "illegal character"
+	UnterminatedString ErrorKind = "unterminated string"
+	MalformedNumber    ErrorKind = "malformed number"
+	MalformedByte      ErrorKind = "malformed byte literal"
+)
+
+// Error is a lexical error. The lexer also emits an ERR token at the position
+// of every error, whose value is the message of the error.
+type Error struct {
+	Kind    ErrorKind
+	Token   token.Token
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Token.Position() + ": " + e.Message
+}
+
 type Lexer struct {
 	input    string
+	filename string
 	line     int
 	col      int
 	position int
 	ch       byte
 	HasError bool
+	errors   []*Error
+
+	// position of the first character of the token being read
+	tokenLine int
+	tokenCol  int
 }
 
 func New(input string) *Lexer {
@@ -21,22 +52,51 @@ func New(input string) *Lexer {
 	return l
 }
 
+// NewFile returns a lexer for the contents of filename, whose tokens record
+// the name of the file they were read from.
+func NewFile(filename, input string) *Lexer {
+	l := New(input)
+	l.filename = filename
+	return l
+}
+
 func (l *Lexer) Tokenize() []token.Token {
 	tokens := []token.Token{}
 	for {
 		l.advance()
-		if l.isAtEnd() {
-			tokens = append(tokens, l.generateToken(token.EOF))
-			break
-		}
 		l.eatWhitespace()
+		l.tokenLine, l.tokenCol = l.line, l.col
 		switch l.ch {
 		case '+':
-			tokens = append(tokens, l.generateToken(token.TOKEN_PLUS))
+			if l.match('+') {
+				tokens = append(tokens, l.generateToken(token.TOKEN_INCREMENT))
+			} else if l.match('=') {
+				tokens = append(tokens, l.generateToken(token.TOKEN_PLUS_ASSIGN))
+			} else {
+				tokens = append(tokens, l.generateToken(token.TOKEN_PLUS))
+			}
 		case '-':
-			tokens = append(tokens, l.generateToken(token.TOKEN_MINUS))
+			if l.match('-') {
+				tokens = append(tokens, l.generateToken(token.TOKEN_DECREMENT))
+			} else if l.match('=') {
+				tokens = append(tokens, l.generateToken(token.TOKEN_MINUS_ASSIGN))
+			} else {
+				tokens = append(tokens, l.generateToken(token.TOKEN_MINUS))
+			}
 		case '*':
-			tokens = append(tokens, l.generateToken(token.TOKEN_MUL))
+			if l.match('*') {
+				tokens = append(tokens, l.generateToken(token.TOKEN_POW))
+			} else if l.match('=') {
+				tokens = append(tokens, l.generateToken(token.TOKEN_MUL_ASSIGN))
+			} else {
+				tokens = append(tokens, l.generateToken(token.TOKEN_MUL))
+			}
+		case '%':
+			if l.match('=') {
+				tokens = append(tokens, l.generateToken(token.TOKEN_MOD_ASSIGN))
+			} else {
+				tokens = append(tokens, l.generateToken(token.TOKEN_MOD))
+			}
 		case '[':
 			tokens = append(tokens, l.generateToken(token.TOKEN_LBRACKET))
 		case ']':
@@ -53,14 +113,38 @@ func (l *Lexer) Tokenize() []token.Token {
 			tokens = append(tokens, l.generateToken(token.TOKEN_SEMICOLON))
 		case ',':
 			tokens = append(tokens, l.generateToken(token.TOKEN_COMMA))
+		case ':':
+			tokens = append(tokens, l.generateToken(token.TOKEN_COLON))
+		case '?':
+			tokens = append(tokens, l.generateToken(token.TOKEN_QUESTION))
+		case '.':
+			if l.peek() == '.' && l.peekNext() == '.' {
+				l.advance()
+				l.advance()
+				tokens = append(tokens, l.generateToken(token.TOKEN_ELLIPSIS))
+			} else {
+				tokens = append(tokens, l.generateToken(token.TOKEN_DOT))
+			}
+		case '&':
+			tokens = append(tokens, l.generateToken(token.TOKEN_BIT_AND))
+		case '|':
+			tokens = append(tokens, l.generateToken(token.TOKEN_BIT_OR))
+		case '^':
+			tokens = append(tokens, l.generateToken(token.TOKEN_BIT_XOR))
+		case '~':
+			tokens = append(tokens, l.generateToken(token.TOKEN_BIT_NOT))
 		case '>':
-			if l.match('=') {
+			if l.match('>') {
+				tokens = append(tokens, l.generateToken(token.TOKEN_SHIFT_RIGHT))
+			} else if l.match('=') {
 				tokens = append(tokens, l.generateToken(token.TOKEN_GTE))
 			} else {
 				tokens = append(tokens, l.generateToken(token.TOKEN_GT))
 			}
 		case '<':
-			if l.match('=') {
+			if l.match('<') {
+				tokens = append(tokens, l.generateToken(token.TOKEN_SHIFT_LEFT))
+			} else if l.match('=') {
 				tokens = append(tokens, l.generateToken(token.TOKEN_LTE))
 			} else {
 				tokens = append(tokens, l.generateToken(token.TOKEN_LT))
@@ -81,40 +165,69 @@ func (l *Lexer) Tokenize() []token.Token {
 			if l.match('/') {
 				l.comment()
 				tokens = append(tokens, l.generateToken(token.COMMENT))
+			} else if l.match('=') {
+				tokens = append(tokens, l.generateToken(token.TOKEN_DIV_ASSIGN))
 			} else {
 				tokens = append(tokens, l.generateToken(token.TOKEN_DIV))
 			}
 		case '"':
 			str, err := l.sstring()
 			if err != nil {
-				tokens = append(tokens, l.generateTokenWithValue(token.ERR, err.Error()))
+				tokens = append(tokens, l.errorToken(UnterminatedString, "%s", err))
 			} else {
 				tokens = append(tokens, l.generateTokenWithValue(token.STRING, str))
 			}
+		case '\'':
+			ch, err := l.char()
+			if err != nil {
+				tokens = append(tokens, l.errorToken(MalformedByte, "%s", err))
+			} else {
+				tokens = append(tokens, l.generateTokenWithValue(token.CHAR, string([]byte{ch})))
+			}
 		case 0:
 			tokens = append(tokens, l.generateToken(token.EOF))
+			return tokens
 		default:
 			if isDigit(l.ch) {
 				tokens = append(tokens, l.number())
 			} else if isAlphaNum(l.ch) {
 				tokens = append(tokens, l.identifier())
 			} else {
-				tokens = append(tokens, l.generateToken(token.ERR))
-				l.HasError = true
+				tokens = append(tokens, l.illegalCharacter())
 			}
 		}
-		if l.isAtEnd() {
-			break
-		}
 	}
-	return tokens
+}
+
+// Errors returns the errors found by Tokenize, in the order of the input.
+func (l *Lexer) Errors() []*Error {
+	return l.errors
+}
+
+// errorToken records an error at the token being read and returns the ERR
+// token that takes the place of the token in the output.
+func (l *Lexer) errorToken(kind ErrorKind, format string, a ...any) token.Token {
+	tok := l.generateTokenWithValue(token.ERR, fmt.Sprintf(format, a...))
+	l.errors = append(l.errors, &Error{Kind: kind, Token: tok, Message: tok.Value})
+	l.HasError = true
+	return tok
+}
+
+// illegalCharacter reports the character starting at the current byte, which
+// may be the first of several bytes encoding it.
+func (l *Lexer) illegalCharacter() token.Token {
+	r, size := utf8.DecodeRuneInString(l.input[l.position-1:])
+	for i := 1; i < size; i++ {
+		l.advance()
+	}
+	return l.errorToken(IllegalCharacter, "illegal character %q", r)
 }
 
 func (l *Lexer) sstring() (string, error) {
 	var buffer bytes.Buffer
 	for l.peek() != '"' {
 		if l.isAtEnd() {
-			return "", fmt.Errorf("%d:%d: unterminated string", l.line, l.col)
+			return "", errors.New("unterminated string")
 		}
 		l.advance()
 		buffer.WriteByte(l.ch)
@@ -123,20 +236,99 @@ func (l *Lexer) sstring() (string, error) {
 	return buffer.String(), nil
 }
 
+// char reads the single, possibly escaped, character of a byte literal such
+// as 'a', '\n' or '\x41'.
+func (l *Lexer) char() (byte, error) {
+	l.advance()
+	ch := l.ch
+	switch ch {
+	case 0, '\n', '\'':
+		return 0, errors.New("empty byte literal")
+	case '\\':
+		l.advance()
+		switch l.ch {
+		case 'n':
+			ch = '\n'
+		case 't':
+			ch = '\t'
+		case 'r':
+			ch = '\r'
+		case '0':
+			ch = 0
+		case '\\', '\'':
+			ch = l.ch
+		case 'x':
+			for i := 0; i < 2; i++ {
+				if !isHexDigit(l.peek()) {
+					l.skipByteLiteral()
+					return 0, errors.New("\\x must be followed by two hex digits")
+				}
+				l.advance()
+			}
+			value, _ := strconv.ParseUint(l.input[l.position-2:l.position], 16, 8)
+			ch = byte(value)
+		default:
+			return 0, fmt.Errorf("unknown escape sequence \\%c", l.ch)
+		}
+	}
+	if l.peek() != '\'' {
+		l.skipByteLiteral()
+		return 0, errors.New("unterminated byte literal")
+	}
+	l.advance()
+	return ch, nil
+}
+
+// skipByteLiteral skips the rest of a malformed byte literal, up to its
+// closing quote, so that it does not produce more errors.
+func (l *Lexer) skipByteLiteral() {
+	for l.peek() != '\'' && l.peek() != '\n' && !l.isAtEnd() {
+		l.advance()
+	}
+	if l.peek() == '\'' {
+		l.advance()
+	}
+}
+
 func (l *Lexer) number() token.Token {
 	var buffer bytes.Buffer
 	buffer.WriteByte(l.ch)
-	hadDot := false
-	for isDigit(l.peek()) || l.peek() == '.' {
-		if l.peek() == '.' && hadDot {
-			return l.generateToken(token.ERR)
+	valid := true
+	if l.ch == '0' && (l.peek() == 'x' || l.peek() == 'X') {
+		l.advance()
+		buffer.WriteByte(l.ch)
+		valid = isHexDigit(l.peek())
+		for isHexDigit(l.peek()) {
+			l.advance()
+			buffer.WriteByte(l.ch)
 		}
-		if l.peek() == '.' {
-			hadDot = true
+	} else {
+		hadDot := false
+		for isDigit(l.peek()) || l.peek() == '.' {
+			if l.peek() == '.' {
+				valid = valid && !hadDot
+				hadDot = true
+			}
+			l.advance()
+			buffer.WriteByte(l.ch)
 		}
+	}
+	// byte literal suffix, as in 65b. Hex numbers read b as a digit instead, a
+	// byte in hex is written '\x41'
+	if l.peek() == 'b' {
+		l.advance()
+		buffer.WriteByte(l.ch)
+	}
+	// the rest of a malformed number such as 1.2.3 or 12ab is read as part of
+	// it, so that it is reported once
+	for isAlphaNum(l.peek()) || l.peek() == '.' {
+		valid = false
 		l.advance()
 		buffer.WriteByte(l.ch)
 	}
+	if !valid {
+		return l.errorToken(MalformedNumber, "malformed number %s", buffer.String())
+	}
 	return l.generateTokenWithValue(token.NUMBER, buffer.String())
 }
 
@@ -161,7 +353,7 @@ func (l *Lexer) identifier() token.Token {
 
 func (l *Lexer) comment() string {
 	var buffer bytes.Buffer
-	for l.peek() != '\n' {
+	for l.peek() != '\n' && !l.isAtEnd() {
 		l.advance()
 		buffer.WriteByte(l.ch)
 	}
@@ -169,28 +361,34 @@ func (l *Lexer) comment() string {
 }
 
 func isAlphaNum(ch byte) bool {
-	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') || ch == '_'
 }
 
 func isDigit(ch byte) bool {
 	return ch >= '0' && ch <= '9'
 }
 
+func isHexDigit(ch byte) bool {
+	return isDigit(ch) || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
 func (l *Lexer) generateTokenWithValue(typez token.TokenType, value string) token.Token {
 	return token.Token{
-		Type:  typez,
-		Value: value,
-		Line:  l.line,
-		Col:   l.col,
+		Type:     typez,
+		Value:    value,
+		Line:     l.tokenLine,
+		Col:      l.tokenCol,
+		Filename: l.filename,
 	}
 }
 
 func (l *Lexer) generateToken(typez token.TokenType) token.Token {
 	return token.Token{
-		Type:  typez,
-		Value: "",
-		Line:  l.line,
-		Col:   l.col,
+		Type:     typez,
+		Value:    "",
+		Line:     l.tokenLine,
+		Col:      l.tokenCol,
+		Filename: l.filename,
 	}
 }
 
@@ -215,6 +413,14 @@ func (l *Lexer) peek() byte {
 	return l.input[l.position]
 }
 
+// peekNext returns the character after the one returned by peek.
+func (l *Lexer) peekNext() byte {
+	if l.position+1 >= len(l.input) {
+		return 0
+	}
+	return l.input[l.position+1]
+}
+
 func (l *Lexer) match(ch byte) bool {
 	if l.isAtEnd() {
 		return false
@@ -126,18 +126,15 @@ and or
 func TestTokenizeInvalidTokens(t *testing.T) {
 	input := "шчш {} if else ELSE"
 	tests := []TestCase{
-		{token.ERR, ""}, // each cyrlic letter is two bytes so the lexer will output two errors for each letter
-		{token.ERR, ""},
-		{token.ERR, ""},
-		{token.ERR, ""},
-		{token.ERR, ""},
-		{token.ERR, ""},
+		{token.ERR, "illegal character 'ш'"},
+		{token.ERR, "illegal character 'ч'"},
+		{token.ERR, "illegal character 'ш'"},
 		{token.TOKEN_LCURLY, ""},
 		{token.TOKEN_RCURLY, ""},
 		{token.TOKEN_IF, ""},
 		{token.TOKEN_ELSE, ""},
 		{token.IDENTIFIER, "ELSE"},
-		// {token.EOF, ""}, // TODO: figure out why we don't get EOF here
+		{token.EOF, ""},
 	}
 	testLexerOutput(t, input, tests)
 }
@@ -193,7 +190,8 @@ func TestUnterminatedString(t *testing.T) {
 		{token.TOKEN_VAR, ""},
 		{token.IDENTIFIER, "a"},
 		{token.TOKEN_ASSIGN, ""},
-		{token.ERR, "3:14: unterminated string"},
+		{token.ERR, "unterminated string"},
+		{token.EOF, ""},
 	}
 	testLexerOutput(t, input, tests)
 }
@@ -209,11 +207,112 @@ func TestUnterminatedStringWithNewlines(t *testing.T) {
 		{token.TOKEN_VAR, ""},
 		{token.IDENTIFIER, "a"},
 		{token.TOKEN_ASSIGN, ""},
-		{token.ERR, "6:1: unterminated string"},
+		{token.ERR, "unterminated string"},
+		{token.EOF, ""},
+	}
+	testLexerOutput(t, input, tests)
+}
+
+func TestUpdateOperators(t *testing.T) {
+	input := `i++ --i a += 1 -= *= /= %= % - + // comment
+	`
+	tests := []TestCase{
+		{token.IDENTIFIER, "i"},
+		{token.TOKEN_INCREMENT, ""},
+		{token.TOKEN_DECREMENT, ""},
+		{token.IDENTIFIER, "i"},
+		{token.IDENTIFIER, "a"},
+		{token.TOKEN_PLUS_ASSIGN, ""},
+		{token.NUMBER, "1"},
+		{token.TOKEN_MINUS_ASSIGN, ""},
+		{token.TOKEN_MUL_ASSIGN, ""},
+		{token.TOKEN_DIV_ASSIGN, ""},
+		{token.TOKEN_MOD_ASSIGN, ""},
+		{token.TOKEN_MOD, ""},
+		{token.TOKEN_MINUS, ""},
+		{token.TOKEN_PLUS, ""},
+		{token.COMMENT, ""},
+		{token.EOF, ""},
 	}
 	testLexerOutput(t, input, tests)
 }
 
+func TestArithmeticOperators(t *testing.T) {
+	input := `a ** b & c | d ^ ~e << 1 >> 2 >= <=`
+	tests := []TestCase{
+		{token.IDENTIFIER, "a"},
+		{token.TOKEN_POW, ""},
+		{token.IDENTIFIER, "b"},
+		{token.TOKEN_BIT_AND, ""},
+		{token.IDENTIFIER, "c"},
+		{token.TOKEN_BIT_OR, ""},
+		{token.IDENTIFIER, "d"},
+		{token.TOKEN_BIT_XOR, ""},
+		{token.TOKEN_BIT_NOT, ""},
+		{token.IDENTIFIER, "e"},
+		{token.TOKEN_SHIFT_LEFT, ""},
+		{token.NUMBER, "1"},
+		{token.TOKEN_SHIFT_RIGHT, ""},
+		{token.NUMBER, "2"},
+		{token.TOKEN_GTE, ""},
+		{token.TOKEN_LTE, ""},
+		{token.EOF, ""},
+	}
+	testLexerOutput(t, input, tests)
+}
+
+func TestByteLiterals(t *testing.T) {
+	input := `'a' '\n' '\'' '\x41' '\xfF' 65b 0x41 0x41b 'ab' '' '\x4' '\xg1' 1`
+	tests := []TestCase{
+		{token.CHAR, "a"},
+		{token.CHAR, "\n"},
+		{token.CHAR, "'"},
+		{token.CHAR, "A"},
+		{token.CHAR, "\xff"},
+		{token.NUMBER, "65b"},
+		{token.NUMBER, "0x41"},
+		{token.NUMBER, "0x41b"},
+		{token.ERR, "unterminated byte literal"},
+		{token.ERR, "empty byte literal"},
+		{token.ERR, "\\x must be followed by two hex digits"},
+		{token.ERR, "\\x must be followed by two hex digits"},
+		{token.NUMBER, "1"},
+		{token.EOF, ""},
+	}
+	testLexerOutput(t, input, tests)
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := "var x = 10;\n\tif x >= \"a\" {"
+	expected := []struct {
+		tokenType token.TokenType
+		line      int
+		col       int
+	}{
+		{token.TOKEN_VAR, 1, 1},
+		{token.IDENTIFIER, 1, 5},
+		{token.TOKEN_ASSIGN, 1, 7},
+		{token.NUMBER, 1, 9},
+		{token.TOKEN_SEMICOLON, 1, 11},
+		{token.TOKEN_IF, 2, 2},
+		{token.IDENTIFIER, 2, 5},
+		{token.TOKEN_GTE, 2, 7},
+		{token.STRING, 2, 10},
+		{token.TOKEN_LCURLY, 2, 14},
+		{token.EOF, 2, 15},
+	}
+	tokens := NewFile("main.m", input).Tokenize()
+	if len(tokens) != len(expected) {
+		t.Fatalf("wrong number of tokens. expected=%d, got=%d", len(expected), len(tokens))
+	}
+	for i, tc := range expected {
+		tok := tokens[i]
+		if tok.Type != tc.tokenType || tok.Line != tc.line || tok.Col != tc.col || tok.Filename != "main.m" {
+			t.Fatalf("tests[%d] - expected %s at main.m:%d:%d, got %s at %s", i, tc.tokenType, tc.line, tc.col, tok.Type, tok.Position())
+		}
+	}
+}
+
 func testLexerOutput(t *testing.T, input string, expectedOutput []TestCase) {
 	l := New(input)
 	tokens := l.Tokenize()
@@ -232,3 +331,95 @@ func testLexerOutput(t *testing.T, input string, expectedOutput []TestCase) {
 		}
 	}
 }
+
+func TestLexerErrors(t *testing.T) {
+	input := "var s = 1.2.3;\nvar t = 5 @ 2;\nvar u = 0x + 12ab;\nvar b = 'ab';\nvar w = \"abc"
+	expected := []struct {
+		kind     ErrorKind
+		position string
+		message  string
+	}{
+		{MalformedNumber, "1:9", "malformed number 1.2.3"},
+		{IllegalCharacter, "2:11", "illegal character '@'"},
+		{MalformedNumber, "3:9", "malformed number 0x"},
+		{MalformedNumber, "3:14", "malformed number 12ab"},
+		{MalformedByte, "4:9", "unterminated byte literal"},
+		{UnterminatedString, "5:9", "unterminated string"},
+	}
+	l := New(input)
+	tokens := l.Tokenize()
+	if !l.HasError {
+		t.Fatalf("HasError not set")
+	}
+	if len(l.Errors()) != len(expected) {
+		t.Fatalf("wrong number of errors. expected=%d, got=%d: %v", len(expected), len(l.Errors()), l.Errors())
+	}
+	for i, tc := range expected {
+		err := l.Errors()[i]
+		if err.Kind != tc.kind || err.Token.Position() != tc.position || err.Message != tc.message {
+			t.Errorf("errors[%d] - expected %s %q at %s, got %s %q at %s", i, tc.kind, tc.message, tc.position, err.Kind, err.Message, err.Token.Position())
+		}
+	}
+	errTokens := 0
+	for _, tok := range tokens {
+		if tok.Type == token.ERR {
+			errTokens++
+		}
+	}
+	if errTokens != len(expected) {
+		t.Errorf("wrong number of ERR tokens. expected=%d, got=%d", len(expected), errTokens)
+	}
+}
+
+func TestCommentAtEndOfInput(t *testing.T) {
+	testLexerOutput(t, "x // no newline", []TestCase{
+		{token.IDENTIFIER, "x"},
+		{token.COMMENT, ""},
+		{token.EOF, ""},
+	})
+}
+
+func TestErrorHandlingTokens(t *testing.T) {
+	input := `try catch finally throw is_error(_x)? snake_case2`
+	tests := []TestCase{
+		{token.TOKEN_TRY, ""},
+		{token.TOKEN_CATCH, ""},
+		{token.TOKEN_FINALLY, ""},
+		{token.TOKEN_THROW, ""},
+		{token.IDENTIFIER, "is_error"},
+		{token.TOKEN_LPAREN, ""},
+		{token.IDENTIFIER, "_x"},
+		{token.TOKEN_RPAREN, ""},
+		{token.TOKEN_QUESTION, ""},
+		{token.IDENTIFIER, "snake_case2"},
+		{token.EOF, ""},
+	}
+	testLexerOutput(t, input, tests)
+}
+
+func TestEllipsis(t *testing.T) {
+	input := `fun f(a, ...rest) { f(...rest); } .. .`
+	tests := []TestCase{
+		{token.TOKEN_FUN, ""},
+		{token.IDENTIFIER, "f"},
+		{token.TOKEN_LPAREN, ""},
+		{token.IDENTIFIER, "a"},
+		{token.TOKEN_COMMA, ""},
+		{token.TOKEN_ELLIPSIS, ""},
+		{token.IDENTIFIER, "rest"},
+		{token.TOKEN_RPAREN, ""},
+		{token.TOKEN_LCURLY, ""},
+		{token.IDENTIFIER, "f"},
+		{token.TOKEN_LPAREN, ""},
+		{token.TOKEN_ELLIPSIS, ""},
+		{token.IDENTIFIER, "rest"},
+		{token.TOKEN_RPAREN, ""},
+		{token.TOKEN_SEMICOLON, ""},
+		{token.TOKEN_RCURLY, ""},
+		{token.TOKEN_DOT, ""},
+		{token.TOKEN_DOT, ""},
+		{token.TOKEN_DOT, ""},
+		{token.EOF, ""},
+	}
+	testLexerOutput(t, input, tests)
+}
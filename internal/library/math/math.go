@@ -0,0 +1,135 @@
+// Package math is the native math library, which scripts load with
+// load("math").
+package math
+
+import (
+	"fmt"
+	"interpreter/internal/evaluator"
+	"interpreter/internal/object"
+	"math"
+)
+
+func init() {
+	evaluator.Register(&object.NativeModule{
+		Name: "math",
+		Functions: map[string]*object.StdFunction{
+			"sqrt":  floatFunction("sqrt", math.Sqrt),
+			"sin":   floatFunction("sin", math.Sin),
+			"cos":   floatFunction("cos", math.Cos),
+			"tan":   floatFunction("tan", math.Tan),
+			"log":   floatFunction("log", math.Log),
+			"floor": intFunction("floor", math.Floor),
+			"ceil":  intFunction("ceil", math.Ceil),
+			"round": intFunction("round", math.Round),
+			"abs":   {Fun: abs},
+			"pow":   {Fun: pow},
+			"min":   {Fun: extreme("min", -1)},
+			"max":   {Fun: extreme("max", 1)},
+		},
+		Constants: map[string]object.Object{
+			"pi": &object.Float{Value: math.Pi},
+			"e":  &object.Float{Value: math.E},
+		},
+	})
+}
+
+// number returns the value of an INTEGER, BYTE or FLOAT argument.
+func number(obj object.Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value), true
+	case *object.Byte:
+		return float64(obj.Value), true
+	case *object.Float:
+		return obj.Value, true
+	}
+	return 0, false
+}
+
+func numberArgument(name string, params []object.Object) (float64, *object.Error) {
+	if len(params) != 1 {
+		return 0, &object.Error{Error: fmt.Sprintf("%s function only accepts one parameter", name)}
+	}
+	x, ok := number(params[0])
+	if !ok {
+		return 0, &object.Error{Error: fmt.Sprintf("%s argument must be a number", name)}
+	}
+	return x, nil
+}
+
+// floatFunction wraps f as a function of one number returning a FLOAT.
+func floatFunction(name string, f func(float64) float64) *object.StdFunction {
+	return &object.StdFunction{
+		Fun: func(params ...object.Object) object.Object {
+			x, err := numberArgument(name, params)
+			if err != nil {
+				return err
+			}
+			return &object.Float{Value: f(x)}
+		},
+	}
+}
+
+// intFunction wraps the rounding function f as a function of one number
+// returning an INTEGER.
+func intFunction(name string, f func(float64) float64) *object.StdFunction {
+	return &object.StdFunction{
+		Fun: func(params ...object.Object) object.Object {
+			x, err := numberArgument(name, params)
+			if err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(f(x))}
+		},
+	}
+}
+
+func abs(params ...object.Object) object.Object {
+	if len(params) == 1 {
+		if i, ok := params[0].(*object.Integer); ok {
+			if i.Value < 0 {
+				return &object.Integer{Value: -i.Value}
+			}
+			return i
+		}
+	}
+	x, err := numberArgument("abs", params)
+	if err != nil {
+		return err
+	}
+	return &object.Float{Value: math.Abs(x)}
+}
+
+func pow(params ...object.Object) object.Object {
+	if len(params) != 2 {
+		return &object.Error{Error: "pow function only accepts two parameters"}
+	}
+	base, ok := number(params[0])
+	exp, ok2 := number(params[1])
+	if !ok || !ok2 {
+		return &object.Error{Error: "pow arguments must be numbers"}
+	}
+	return &object.Float{Value: math.Pow(base, exp)}
+}
+
+// extreme returns a function giving the smallest argument when sign is -1
+// and the largest when it is 1. The argument is returned as it was passed.
+func extreme(name string, sign float64) func(params ...object.Object) object.Object {
+	return func(params ...object.Object) object.Object {
+		if len(params) == 0 {
+			return &object.Error{Error: fmt.Sprintf("%s function expects at least one parameter", name)}
+		}
+		var best object.Object
+		var bestValue float64
+		for _, param := range params {
+			x, ok := number(param)
+			if !ok {
+				return &object.Error{Error: fmt.Sprintf("%s arguments must be numbers", name)}
+			}
+			if best == nil || (x-bestValue)*sign > 0 {
+				best, bestValue = param, x
+			}
+		}
+		return best
+	}
+}
@@ -2,12 +2,15 @@ package object
 
 type Environment struct {
 	store     map[string]Object
+	types     map[string]ObjectType
 	enclosing *Environment
+	frame     *Frame
 }
 
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s, enclosing: nil}
+	t := make(map[string]ObjectType)
+	return &Environment{store: s, types: t, enclosing: nil}
 }
 
 func (e *Environment) Set(name string, val Object) Object {
@@ -15,6 +18,44 @@ func (e *Environment) Set(name string, val Object) Object {
 	return val
 }
 
+// SetTyped declares name in this scope as a variable that only holds values
+// of type typ.
+func (e *Environment) SetTyped(name string, val Object, typ ObjectType) Object {
+	e.types[name] = typ
+	return e.Set(name, val)
+}
+
+// DeclaredType returns the type name was declared with in the closest scope
+// that declares it, or "" if that declaration is untyped.
+func (e *Environment) DeclaredType(name string) ObjectType {
+	for env := e; env != nil; env = env.enclosing {
+		if _, ok := env.store[name]; ok {
+			return env.types[name]
+		}
+	}
+	return ""
+}
+
+// Assign updates name in the closest scope that declares it, so closures and
+// nested blocks can modify variables of the scopes enclosing them. It reports
+// false when no scope declares name.
+func (e *Environment) Assign(name string, val Object) bool {
+	for env := e; env != nil; env = env.enclosing {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = val
+			return true
+		}
+	}
+	return false
+}
+
+// Declared reports whether name is declared in this scope, ignoring the
+// scopes enclosing it.
+func (e *Environment) Declared(name string) bool {
+	_, ok := e.store[name]
+	return ok
+}
+
 func (e *Environment) Get(name string) (Object, bool) {
 	obj, ok := e.store[name]
 	if !ok && e.enclosing != nil {
@@ -23,8 +64,32 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return obj, ok
 }
 
+// Bindings returns the names declared in this scope, ignoring the scopes
+// enclosing it. The map is not a copy, it sees later declarations.
+func (e *Environment) Bindings() map[string]Object {
+	return e.store
+}
+
 func NewEnclosedEnvironment(enc *Environment) *Environment {
 	env := NewEnvironment()
 	env.enclosing = enc
 	return env
 }
+
+// NewFrameEnvironment creates the environment a function call runs in.
+func NewFrameEnvironment(enc *Environment, frame *Frame) *Environment {
+	env := NewEnclosedEnvironment(enc)
+	env.frame = frame
+	return env
+}
+
+// Frame returns the innermost function call env belongs to, or nil at the
+// top level.
+func (e *Environment) Frame() *Frame {
+	for env := e; env != nil; env = env.enclosing {
+		if env.frame != nil {
+			return env.frame
+		}
+	}
+	return nil
+}
@@ -3,7 +3,10 @@ package object
 import (
 	"bytes"
 	"fmt"
+	"hash/fnv"
 	"interpreter/internal/ast"
+	"interpreter/internal/token"
+	"slices"
 	"strings"
 )
 
@@ -17,14 +20,26 @@ type ObjectType string
 const (
 	INTEGER_OBJ      = "INTEGER"
 	FLOAT_OBJ        = "FLOAT"
+	BYTE_OBJ         = "BYTE"
+	BYTES_OBJ        = "BYTES"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
 	ERROR_OBJ        = "ERROR"
 	FUNCTION_OBJ     = "FUNCTION"
 	STRING_OBJ       = "STRING"
 	ARRAY_OBJ        = "ARRAY"
+	HASH_OBJ         = "HASH"
 	STDFUNC_OBJ      = "STDFUNC"
+	ERROR_VALUE_OBJ  = "ERROR_VALUE"
+	NAMESPACE_OBJ    = "NAMESPACE"
+	STRUCT_TYPE_OBJ  = "STRUCT_TYPE"
+	STRUCT_OBJ       = "STRUCT"
+	CLASS_OBJ        = "CLASS"
+	INSTANCE_OBJ     = "INSTANCE"
+	SUPER_OBJ        = "SUPER"
 )
 
 type Integer struct {
@@ -41,6 +56,22 @@ type Float struct {
 func (i *Float) Inspect() string  { return fmt.Sprintf("%f", i.Value) }
 func (i *Float) Type() ObjectType { return FLOAT_OBJ }
 
+type Byte struct {
+	Value byte
+}
+
+func (b *Byte) Inspect() string  { return fmt.Sprintf("%d", b.Value) }
+func (b *Byte) Type() ObjectType { return BYTE_OBJ }
+
+// Bytes is a mutable buffer of raw bytes, used for binary data such as the
+// contents of a file.
+type Bytes struct {
+	Value []byte
+}
+
+func (b *Bytes) Inspect() string  { return fmt.Sprintf("b%q", b.Value) }
+func (b *Bytes) Type() ObjectType { return BYTES_OBJ }
+
 type Boolean struct {
 	Value bool
 }
@@ -60,19 +91,269 @@ type ReturnValue struct {
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 
+type Break struct {
+	Label string
+}
+
+func (b *Break) Inspect() string  { return "break" }
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+
+type Continue struct {
+	Label string
+}
+
+func (c *Continue) Inspect() string  { return "continue" }
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+
+// ErrorKind tells scripts that catch an error what kind of failure it was.
+type ErrorKind string
+
+const (
+	RUNTIME_ERROR ErrorKind = "runtime"
+	TYPE_ERROR    ErrorKind = "type"
+	IO_ERROR      ErrorKind = "io"
+	USER_ERROR    ErrorKind = "user"
+)
+
 type Error struct {
 	Error string
+	// Kind is left empty for RUNTIME_ERROR.
+	Kind ErrorKind
+	// Value is the value passed to throw, if the error was thrown by a script.
+	Value Object
+	// Propagating is set on the error raised by the ? operator to unwind to
+	// the enclosing function, which returns the error value it holds.
+	Propagating *ErrorValue
+	// Token is the token of the node whose evaluation failed.
+	Token token.Token
+	// Stack lists the function calls that were active when the error was
+	// raised, innermost first.
+	Stack []*Frame
 }
 
 func (e *Error) Inspect() string  { return fmt.Sprintf("ERROR: %s", e.Error) }
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 
-type Function struct {
-	Params []ast.IdentifierExpression
-	Body   *ast.BlockStatement
+// ErrorValue is an error that was caught, which scripts can inspect and pass
+// around like any other value instead of it unwinding the program.
+type ErrorValue struct {
+	Err *Error
+}
+
+func (ev *ErrorValue) Inspect() string  { return fmt.Sprintf("error: %s", ev.Err.Error) }
+func (ev *ErrorValue) Type() ObjectType { return ERROR_VALUE_OBJ }
+
+// Attribute returns the field of the error called name, as in e.message. The
+// value field is nil when the error was not thrown with a value.
+func (ev *ErrorValue) Attribute(name string) (Object, bool) {
+	err := ev.Err
+	switch name {
+	case "message":
+		return &String{Value: err.Error}, true
+	case "kind":
+		if err.Kind == "" {
+			return &String{Value: string(RUNTIME_ERROR)}, true
+		}
+		return &String{Value: string(err.Kind)}, true
+	case "file":
+		return &String{Value: err.Token.Filename}, true
+	case "line":
+		return &Integer{Value: int64(err.Token.Line)}, true
+	case "column":
+		return &Integer{Value: int64(err.Token.Col)}, true
+	case "value":
+		return err.Value, true
+	case "stack":
+		stack := []Object{}
+		for _, frame := range err.Stack {
+			stack = append(stack, &String{Value: frame.String()})
+		}
+		return &Array{Elements: stack}, true
+	}
+	return nil, false
+}
+
+// Attributable is implemented by objects with members scripts look up with
+// the dot operator, as in util.add.
+type Attributable interface {
+	Object
+	Attribute(name string) (Object, bool)
+}
+
+// Namespace holds the names a module exposes, which scripts look up with the
+// dot operator or by indexing it with the name.
+type Namespace struct {
+	Name    string
+	Members map[string]Object
+}
+
+func (n *Namespace) Inspect() string  { return fmt.Sprintf("<namespace %s>", n.Name) }
+func (n *Namespace) Type() ObjectType { return NAMESPACE_OBJ }
+
+func (n *Namespace) Attribute(name string) (Object, bool) {
+	member, ok := n.Members[name]
+	return member, ok
+}
+
+// StructType is a declared struct. Calling it creates a Struct, binding its
+// fields like a function binds its parameters.
+type StructType struct {
+	Name   string
+	Fields []ast.IdentifierExpression
 	Env    *Environment
 }
 
+func (st *StructType) Inspect() string  { return fmt.Sprintf("<struct %s>", st.Name) }
+func (st *StructType) Type() ObjectType { return STRUCT_TYPE_OBJ }
+
+// Struct is a value of a struct type. Its fields are stored in an environment
+// of their own, which records the types they were declared with.
+type Struct struct {
+	StructType *StructType
+	Fields     *Environment
+}
+
+func (s *Struct) Type() ObjectType { return STRUCT_OBJ }
+func (s *Struct) Inspect() string {
+	fields := []string{}
+	for _, field := range s.StructType.Fields {
+		value, _ := s.Fields.Get(field.Value)
+		fields = append(fields, field.Value+": "+value.Inspect())
+	}
+	return s.StructType.Name + "{" + strings.Join(fields, ", ") + "}"
+}
+
+func (s *Struct) Attribute(name string) (Object, bool) {
+	return s.Fields.Get(name)
+}
+
+// Class is a declared class. Calling it creates an Instance and calls its init
+// method, if it has one, with the arguments.
+type Class struct {
+	Name    string
+	Parent  *Class
+	Methods map[string]*Function
+}
+
+func (c *Class) Inspect() string  { return fmt.Sprintf("<class %s>", c.Name) }
+func (c *Class) Type() ObjectType { return CLASS_OBJ }
+
+// Method looks up the method called name in the class and the classes it
+// inherits from, returning it with the class that defines it.
+func (c *Class) Method(name string) (*Function, *Class) {
+	for class := c; class != nil; class = class.Parent {
+		if method, ok := class.Methods[name]; ok {
+			return method, class
+		}
+	}
+	return nil, nil
+}
+
+// Bind returns method, defined by class, bound to receiver. Inside the bound
+// method super refers to the methods of the parent of class.
+func Bind(method *Function, class *Class, receiver Object) *Function {
+	bound := *method
+	bound.Receiver = receiver
+	if class.Parent != nil {
+		bound.Env = NewEnclosedEnvironment(method.Env)
+		bound.Env.Set("super", &Super{Class: class.Parent, Receiver: receiver})
+	}
+	return &bound
+}
+
+// Instance is an object of a class. Its fields, which methods create by
+// assigning to them, are stored in an environment of their own.
+type Instance struct {
+	Class  *Class
+	Fields *Environment
+}
+
+func (i *Instance) Type() ObjectType { return INSTANCE_OBJ }
+func (i *Instance) Inspect() string {
+	names := []string{}
+	for name := range i.Fields.Bindings() {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	fields := []string{}
+	for _, name := range names {
+		value, _ := i.Fields.Get(name)
+		fields = append(fields, name+": "+value.Inspect())
+	}
+	return i.Class.Name + "{" + strings.Join(fields, ", ") + "}"
+}
+
+// Attribute returns the field called name, or else the method called name
+// bound to the instance.
+func (i *Instance) Attribute(name string) (Object, bool) {
+	if field, ok := i.Fields.Get(name); ok {
+		return field, true
+	}
+	method, class := i.Class.Method(name)
+	if method == nil {
+		return nil, false
+	}
+	return Bind(method, class, i), true
+}
+
+// Super gives access to the methods a class inherits, bound to the instance
+// the method using super was called on.
+type Super struct {
+	Class    *Class
+	Receiver Object
+}
+
+func (s *Super) Inspect() string  { return fmt.Sprintf("<super %s>", s.Class.Name) }
+func (s *Super) Type() ObjectType { return SUPER_OBJ }
+
+func (s *Super) Attribute(name string) (Object, bool) {
+	method, class := s.Class.Method(name)
+	if method == nil {
+		return nil, false
+	}
+	return Bind(method, class, s.Receiver), true
+}
+
+// NativeModule is a library implemented in Go. Loading it gives a namespace
+// of its functions and constants.
+type NativeModule struct {
+	Name      string
+	Functions map[string]*StdFunction
+	Constants map[string]Object
+}
+
+// Member returns the function or constant of the module called name.
+func (m *NativeModule) Member(name string) (Object, bool) {
+	if fn, ok := m.Functions[name]; ok {
+		return fn, true
+	}
+	obj, ok := m.Constants[name]
+	return obj, ok
+}
+
+// Namespace returns the namespace scripts see when they load the module.
+func (m *NativeModule) Namespace() *Namespace {
+	members := make(map[string]Object, len(m.Functions)+len(m.Constants))
+	for name, fn := range m.Functions {
+		members[name] = fn
+	}
+	for name, obj := range m.Constants {
+		members[name] = obj
+	}
+	return &Namespace{Name: m.Name, Members: members}
+}
+
+type Function struct {
+	Name       string
+	Params     []ast.IdentifierExpression
+	ReturnType token.Token
+	Body       *ast.BlockStatement
+	Env        *Environment
+	// Receiver is the instance a bound method was looked up on, which is
+	// passed as its first argument.
+	Receiver Object
+}
+
 func (e *Function) Inspect() string  { return "<fun>" }
 func (e *Function) Type() ObjectType { return FUNCTION_OBJ }
 
@@ -102,7 +383,130 @@ func (ao *Array) Inspect() string {
 
 type StdFunction struct {
 	Fun func(args ...Object) Object
+	// EnvFun is called instead of Fun when set, for builtins that need the
+	// environment they are called from.
+	EnvFun func(env *Environment, args ...Object) Object
 }
 
 func (sf *StdFunction) Type() ObjectType { return STDFUNC_OBJ }
 func (sf *StdFunction) Inspect() string  { return "<std fun>" }
+
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Hashable is implemented by the objects that can be used as hash keys.
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (b *Byte) HashKey() HashKey {
+	return HashKey{Type: b.Type(), Value: uint64(b.Value)}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash maps hashable keys to values and remembers the order in which the keys
+// were first inserted, which is the order Entries and Inspect use.
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	order []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+	pairs := []string{}
+	for _, pair := range h.Entries() {
+		pairs = append(pairs, pair.Key.Inspect()+": "+pair.Value.Inspect())
+	}
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	pair, ok := h.Pairs[key.HashKey()]
+	return pair.Value, ok
+}
+
+func (h *Hash) Set(key Hashable, val Object) {
+	hashKey := key.HashKey()
+	if _, ok := h.Pairs[hashKey]; !ok {
+		h.order = append(h.order, hashKey)
+	}
+	h.Pairs[hashKey] = HashPair{Key: key, Value: val}
+}
+
+func (h *Hash) Delete(key Hashable) (Object, bool) {
+	hashKey := key.HashKey()
+	pair, ok := h.Pairs[hashKey]
+	if !ok {
+		return nil, false
+	}
+	delete(h.Pairs, hashKey)
+	h.order = slices.DeleteFunc(h.order, func(k HashKey) bool { return k == hashKey })
+	return pair.Value, true
+}
+
+// Entries returns the pairs of the hash in insertion order.
+func (h *Hash) Entries() []HashPair {
+	entries := make([]HashPair, 0, len(h.order))
+	for _, key := range h.order {
+		entries = append(entries, h.Pairs[key])
+	}
+	return entries
+}
+
+// Frame is a call of a user function that has not returned yet.
+type Frame struct {
+	Function string
+	// Token is the token of the call expression that made the call.
+	Token  token.Token
+	Caller *Frame
+}
+
+func (f *Frame) String() string {
+	name := f.Function
+	if name == "" {
+		name = "<fun>"
+	}
+	return fmt.Sprintf("%s (%s)", name, f.Token.Position())
+}
+
+// Stack returns f and the frames of its callers, innermost first.
+func (f *Frame) Stack() []*Frame {
+	var stack []*Frame
+	for frame := f; frame != nil; frame = frame.Caller {
+		stack = append(stack, frame)
+	}
+	return stack
+}
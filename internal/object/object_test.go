@@ -54,6 +54,11 @@ func TestReturnValue(t *testing.T) {
 	testObjectInspect(t, 0, &object.ReturnValue{Value: &object.Boolean{true}}, "true")
 }
 
+func TestByteInspect(t *testing.T) {
+	testObjectInspect(t, 0, &object.Byte{Value: 'a'}, "97")
+	testObjectInspect(t, 1, &object.Bytes{Value: []byte("a\x00")}, `b"a\x00"`)
+}
+
 func TestBoolInspect(t *testing.T) {
 	testObjectInspect(t, 0, &object.Boolean{Value: true}, "true")
 }
@@ -61,6 +66,32 @@ func TestNullInspect(t *testing.T) {
 	testObjectInspect(t, 0, &object.Null{}, "null")
 }
 
+func TestHashInspect(t *testing.T) {
+	hash := object.NewHash()
+	hash.Set(&object.String{Value: "b"}, &object.Integer{Value: 1})
+	hash.Set(&object.Integer{Value: 2}, &object.Boolean{Value: true})
+	hash.Set(&object.String{Value: "a"}, &object.String{Value: "x"})
+	testObjectInspect(t, 0, hash, "{b: 1, 2: true, a: x}")
+
+	hash.Set(&object.String{Value: "b"}, &object.Integer{Value: 3})
+	testObjectInspect(t, 1, hash, "{b: 3, 2: true, a: x}")
+
+	hash.Delete(&object.Integer{Value: 2})
+	testObjectInspect(t, 2, hash, "{b: 3, a: x}")
+}
+
+func TestHashKey(t *testing.T) {
+	if (&object.String{Value: "a"}).HashKey() != (&object.String{Value: "a"}).HashKey() {
+		t.Fatalf("equal strings have different hash keys")
+	}
+	if (&object.String{Value: "a"}).HashKey() == (&object.String{Value: "b"}).HashKey() {
+		t.Fatalf("different strings have the same hash key")
+	}
+	if (&object.Integer{Value: 1}).HashKey() == (&object.Boolean{Value: true}).HashKey() {
+		t.Fatalf("integer and boolean share a hash key")
+	}
+}
+
 func testObjectInspect(t *testing.T, tstNum int, obj object.Object, expected string) {
 	result := obj.Inspect()
 	if result != expected {
@@ -5,6 +5,7 @@ import (
 	"interpreter/internal/ast"
 	"interpreter/internal/lexer"
 	"interpreter/internal/token"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -12,31 +13,54 @@ import (
 const (
 	_ int = iota
 	LOWEST
-	ASSIGN
 	LOGICAL
+	EQUALS
 	LESSGREATER
 	SUM
 	PRODUCT
 	PREFIX
+	POWER
+	POSTFIX
 	CALL
 	INDEX
 )
 
 var precedences = map[token.TokenType]int{
-	token.TOKEN_EQUAL:     ASSIGN,
-	token.TOKEN_NOT_EQUAL: ASSIGN,
-	token.TOKEN_AND:       LOGICAL,
-	token.TOKEN_OR:        LOGICAL,
-	token.TOKEN_LT:        LESSGREATER,
-	token.TOKEN_GT:        LESSGREATER,
-	token.TOKEN_LTE:       LESSGREATER,
-	token.TOKEN_GTE:       LESSGREATER,
-	token.TOKEN_PLUS:      SUM,
-	token.TOKEN_MINUS:     SUM,
-	token.TOKEN_MUL:       PRODUCT,
-	token.TOKEN_DIV:       PRODUCT,
-	token.TOKEN_LPAREN:    CALL,
-	token.TOKEN_LBRACKET:  INDEX,
+	token.TOKEN_AND:         LOGICAL,
+	token.TOKEN_OR:          LOGICAL,
+	token.TOKEN_EQUAL:       EQUALS,
+	token.TOKEN_NOT_EQUAL:   EQUALS,
+	token.TOKEN_LT:          LESSGREATER,
+	token.TOKEN_GT:          LESSGREATER,
+	token.TOKEN_LTE:         LESSGREATER,
+	token.TOKEN_GTE:         LESSGREATER,
+	token.TOKEN_PLUS:        SUM,
+	token.TOKEN_MINUS:       SUM,
+	token.TOKEN_BIT_OR:      SUM,
+	token.TOKEN_BIT_XOR:     SUM,
+	token.TOKEN_MUL:         PRODUCT,
+	token.TOKEN_DIV:         PRODUCT,
+	token.TOKEN_MOD:         PRODUCT,
+	token.TOKEN_BIT_AND:     PRODUCT,
+	token.TOKEN_SHIFT_LEFT:  PRODUCT,
+	token.TOKEN_SHIFT_RIGHT: PRODUCT,
+	token.TOKEN_POW:         POWER,
+	token.TOKEN_INCREMENT:   POSTFIX,
+	token.TOKEN_DECREMENT:   POSTFIX,
+	token.TOKEN_QUESTION:    POSTFIX,
+	token.TOKEN_LPAREN:      CALL,
+	token.TOKEN_LBRACKET:    INDEX,
+	token.TOKEN_DOT:         INDEX,
+}
+
+// compoundAssignments maps each compound assignment token to the infix
+// operator it applies to its target.
+var compoundAssignments = map[token.TokenType]string{
+	token.TOKEN_PLUS_ASSIGN:  token.TOKEN_PLUS,
+	token.TOKEN_MINUS_ASSIGN: token.TOKEN_MINUS,
+	token.TOKEN_MUL_ASSIGN:   token.TOKEN_MUL,
+	token.TOKEN_DIV_ASSIGN:   token.TOKEN_DIV,
+	token.TOKEN_MOD_ASSIGN:   token.TOKEN_MOD,
 }
 
 type (
@@ -44,14 +68,42 @@ type (
 	infixParseFn  func(ast.Expression) ast.Expression
 )
 
+// Error is a syntax error and the token it was found at.
+type Error struct {
+	Token   token.Token
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Token.Position() + ": " + e.Message
+}
+
 type Parser struct {
 	l      *lexer.Lexer
-	errors []string
+	errors []*Error
 
 	tokens    []token.Token
 	curToken  token.Token
 	peekToken token.Token
 
+	// panicking is set by the first syntax error of a statement and silences
+	// the errors that follow from it until the parser has skipped to the next
+	// statement.
+	panicking bool
+
+	// depth is the number of { before the current token that are not closed
+	// yet, which tells synchronize where the failed statement ends.
+	depth int
+
+	// loops holds the labels of the loops enclosing the statement being
+	// parsed, innermost last; unlabelled loops are recorded as "".
+	loops []string
+	// inIfExpression is set while parsing the blocks of an if used as an
+	// expression, which return can not leave; break and continue can not leave
+	// it either, so they only target the loops after the first ifLoops ones.
+	inIfExpression bool
+	ifLoops        int
+
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
@@ -64,16 +116,39 @@ func (p *Parser) peekTokenIs(t token.TokenType) bool {
 	return p.peekToken.Type == t
 }
 
+// peekSecondTokenIs checks the token that follows the peek token.
+func (p *Parser) peekSecondTokenIs(t token.TokenType) bool {
+	return len(p.tokens) > 0 && p.tokens[0].Type == t
+}
+
+func (p *Parser) errorf(tok token.Token, format string, a ...any) {
+	// an ERR token has been reported by the lexer already
+	if p.panicking || tok.Type == token.ERR {
+		p.panicking = true
+		return
+	}
+	p.errors = append(p.errors, &Error{Token: tok, Message: fmt.Sprintf(format, a...)})
+	p.panicking = true
+}
+
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.errorf(p.peekToken, "expected next token to be %s, got %s instead", t, p.peekToken.Type)
 }
 
 func (p *Parser) nextToken() {
 	// TODO: use channels or directly call NextToken, don't use arrays
+	switch p.curToken.Type {
+	case token.TOKEN_LCURLY:
+		p.depth++
+	case token.TOKEN_RCURLY:
+		p.depth--
+	}
 	p.curToken = p.peekToken
+	// the lexer leaves an ERR token for each of its errors, which are reported
+	// even while skipping a statement; the statement holding one is dropped
 	if p.curToken.Type == token.ERR {
-		p.errors = append(p.errors, "received an error token")
+		p.errors = append(p.errors, &Error{Token: p.curToken, Message: p.curToken.Value})
+		p.panicking = true
 	}
 	if len(p.tokens) == 1 {
 		p.peekToken = p.tokens[0]
@@ -93,7 +168,11 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 }
 
 func (p *Parser) curIsTypeToken() bool {
-	return p.curToken.Type == token.TOKEN_INT || p.curToken.Type == token.TOKEN_STRING || p.curToken.Type == token.TOKEN_BOOL || p.curToken.Type == token.TOKEN_BYTE || p.curToken.Type == token.TOKEN_FLOAT
+	return isTypeToken(p.curToken.Type)
+}
+
+func isTypeToken(t token.TokenType) bool {
+	return t == token.TOKEN_INT || t == token.TOKEN_STRING || t == token.TOKEN_BOOL || t == token.TOKEN_BYTE || t == token.TOKEN_FLOAT
 }
 
 func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
@@ -105,24 +184,48 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 }
 
 func New(l *lexer.Lexer) *Parser {
-	p := &Parser{l: l, errors: []string{}}
-	p.tokens = l.Tokenize()
+	p := &Parser{l: l, errors: []*Error{}}
+	// comments do not take part in the syntax
+	p.tokens = slices.DeleteFunc(l.Tokenize(), func(t token.Token) bool {
+		return t.Type == token.COMMENT
+	})
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENTIFIER, p.parseIdentifier)
 	p.registerPrefix(token.NUMBER, p.parseNumberExpression)
 	p.registerPrefix(token.STRING, p.parseStringExpression)
+	p.registerPrefix(token.TOKEN_SUPER, p.parseSuperExpression)
+	p.registerPrefix(token.CHAR, p.parseCharExpression)
+	p.registerPrefix(token.TOKEN_INT, p.parseTypeIdentifier)
+	p.registerPrefix(token.TOKEN_BYTE, p.parseTypeIdentifier)
+	p.registerPrefix(token.TOKEN_STRING, p.parseTypeIdentifier)
 	p.registerPrefix(token.TOKEN_TRUE, p.parseBoolExpression)
 	p.registerPrefix(token.TOKEN_FALSE, p.parseBoolExpression)
 	p.registerPrefix(token.TOKEN_BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.TOKEN_MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TOKEN_BIT_NOT, p.parsePrefixExpression)
+	p.registerPrefix(token.TOKEN_INCREMENT, p.parsePrefixUpdateExpression)
+	p.registerPrefix(token.TOKEN_DECREMENT, p.parsePrefixUpdateExpression)
 	p.registerPrefix(token.TOKEN_LBRACKET, p.parseArrayExpression)
 	p.registerPrefix(token.TOKEN_LPAREN, p.parseGroupExpression)
+	p.registerPrefix(token.TOKEN_IF, p.parseIfExpression)
+	p.registerPrefix(token.TOKEN_FUN, p.parseFunctionLiteral)
+	p.registerPrefix(token.TOKEN_LCURLY, p.parseHashLiteral)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.TOKEN_PLUS, p.parseInfixExpression)
 	p.registerInfix(token.TOKEN_MINUS, p.parseInfixExpression)
 	p.registerInfix(token.TOKEN_MUL, p.parseInfixExpression)
 	p.registerInfix(token.TOKEN_DIV, p.parseInfixExpression)
+	p.registerInfix(token.TOKEN_MOD, p.parseInfixExpression)
+	p.registerInfix(token.TOKEN_POW, p.parseInfixExpression)
+	p.registerInfix(token.TOKEN_BIT_AND, p.parseInfixExpression)
+	p.registerInfix(token.TOKEN_BIT_OR, p.parseInfixExpression)
+	p.registerInfix(token.TOKEN_BIT_XOR, p.parseInfixExpression)
+	p.registerInfix(token.TOKEN_SHIFT_LEFT, p.parseInfixExpression)
+	p.registerInfix(token.TOKEN_SHIFT_RIGHT, p.parseInfixExpression)
+	p.registerInfix(token.TOKEN_INCREMENT, p.parsePostfixUpdateExpression)
+	p.registerInfix(token.TOKEN_DECREMENT, p.parsePostfixUpdateExpression)
+	p.registerInfix(token.TOKEN_QUESTION, p.parsePropagateExpression)
 	p.registerInfix(token.TOKEN_EQUAL, p.parseInfixExpression)
 	p.registerInfix(token.TOKEN_NOT_EQUAL, p.parseInfixExpression)
 	p.registerInfix(token.TOKEN_GT, p.parseInfixExpression)
@@ -132,6 +235,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.TOKEN_OR, p.parseInfixExpression)
 	p.registerInfix(token.TOKEN_AND, p.parseInfixExpression)
 	p.registerInfix(token.TOKEN_LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.TOKEN_DOT, p.parseMemberExpression)
 	p.registerInfix(token.TOKEN_LPAREN, p.parseCallExpression)
 	p.nextToken()
 	p.nextToken()
@@ -142,8 +246,10 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 	for p.curToken.Type != token.EOF {
-		stmt := p.parseStatement()
-		if stmt != nil {
+		if p.curTokenIs(token.TOKEN_RCURLY) {
+			p.errorf(p.curToken, "unexpected } without matching {")
+			p.panicking = false
+		} else if stmt := p.parseNextStatement(); stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
 		p.nextToken()
@@ -151,35 +257,130 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
+// parseNextStatement parses the statement starting at the current token. A
+// statement with syntax errors is dropped and the parser skips to the point
+// the next statement can be parsed from.
+func (p *Parser) parseNextStatement() ast.Statement {
+	start := p.depth
+	stmt := p.parseStatement()
+	if p.panicking {
+		p.synchronize(start)
+		return nil
+	}
+	return stmt
+}
+
+// synchronize skips the rest of a statement that failed to parse, which
+// started at the brace depth start. It stops at the ; ending the statement,
+// after a block the statement opened and the ; following it, or before a token
+// that ends the enclosing block or starts another statement.
+func (p *Parser) synchronize(start int) {
+	defer func() { p.panicking = false }()
+	for !p.curTokenIs(token.EOF) {
+		// depth counts the current token as well
+		depth := p.depth
+		switch p.curToken.Type {
+		case token.TOKEN_LCURLY:
+			depth++
+		case token.TOKEN_RCURLY:
+			depth--
+			if depth < start {
+				return
+			}
+			if depth == start {
+				if p.peekTokenIs(token.TOKEN_SEMICOLON) {
+					p.nextToken()
+				}
+				return
+			}
+		}
+		if depth == start {
+			if p.curTokenIs(token.TOKEN_SEMICOLON) || p.peekTokenIs(token.TOKEN_RCURLY) ||
+				p.peekTokenIs(token.EOF) || startsStatement(p.peekToken.Type) {
+				return
+			}
+		}
+		p.nextToken()
+	}
+}
+
+// startsStatement reports whether t is a keyword that can only start a
+// statement.
+func startsStatement(t token.TokenType) bool {
+	switch t {
+	case token.TOKEN_VAR, token.TOKEN_FUN, token.TOKEN_IF, token.TOKEN_WHILE, token.TOKEN_FOR,
+		token.TOKEN_RETURN, token.TOKEN_BREAK, token.TOKEN_CONTINUE, token.TOKEN_TRY, token.TOKEN_THROW,
+		token.TOKEN_IMPORT, token.TOKEN_STRUCT, token.TOKEN_CLASS:
+		return true
+	}
+	return false
+}
+
+// expectSemicolon consumes the ; that ends a statement. It can only be left
+// out before the } closing a block and at the end of the input.
+func (p *Parser) expectSemicolon() {
+	if p.peekTokenIs(token.TOKEN_SEMICOLON) {
+		p.nextToken()
+		return
+	}
+	if p.panicking || p.peekTokenIs(token.TOKEN_RCURLY) || p.peekTokenIs(token.EOF) {
+		return
+	}
+	p.errorf(p.peekToken, "expected ; at end of statement, got %s", p.peekToken.Type)
+	// the statement itself is complete, so parsing resumes at the next token
+	p.panicking = false
+}
+
 func (p *Parser) parseStatement() ast.Statement {
+	if p.curIsTypeToken() && p.peekTokenIs(token.IDENTIFIER) {
+		return p.parseVarStatement()
+	}
 	switch p.curToken.Type {
 	case token.TOKEN_VAR:
 		return p.parseVarStatement()
 	case token.IDENTIFIER:
-		if p.peekToken.Type == token.TOKEN_ASSIGN {
-			return p.parseVarStatement()
+		if p.peekToken.Type == token.TOKEN_COLON {
+			return p.parseLabeledStatement()
 		}
 	case token.TOKEN_WHILE:
-		return p.parseWhileStatement()
+		return p.parseWhileStatement(nil)
+	case token.TOKEN_FOR:
+		return p.parseForStatement(nil)
+	case token.TOKEN_BREAK:
+		return p.parseBreakStatement()
+	case token.TOKEN_CONTINUE:
+		return p.parseContinueStatement()
 	case token.TOKEN_IF:
 		return p.parseIfStatement()
 	case token.TOKEN_RETURN:
 		return p.parseReturnStatement()
+	case token.TOKEN_TRY:
+		return p.parseTryStatement()
+	case token.TOKEN_THROW:
+		return p.parseThrowStatement()
+	case token.TOKEN_IMPORT:
+		return p.parseImportStatement()
+	case token.TOKEN_STRUCT:
+		return p.parseStructStatement()
+	case token.TOKEN_CLASS:
+		return p.parseClassStatement()
 	case token.TOKEN_LCURLY:
-		return p.parseBlockStatement()
+		if !p.startsHashLiteral() {
+			return p.parseBlockStatement()
+		}
 	case token.TOKEN_FUN:
-		return p.parseFunctionDefinition()
+		if !p.peekTokenIs(token.TOKEN_LPAREN) {
+			return p.parseFunctionDefinition()
+		}
 	}
 	return p.parseExpressionStatement()
 }
 
 func (p *Parser) parseVarStatement() *ast.VarStatement {
 	stmt := &ast.VarStatement{Token: p.curToken}
-	if p.curToken.Type == token.TOKEN_VAR {
-		p.nextToken()
-	}
+	p.nextToken()
 	if p.curToken.Type != token.IDENTIFIER {
-		p.errors = append(p.errors, "expected identifier")
+		p.errorf(p.curToken, "expected identifier")
 		return nil
 	}
 	stmt.Identifier = &ast.IdentifierExpression{
@@ -190,38 +391,218 @@ func (p *Parser) parseVarStatement() *ast.VarStatement {
 	if p.curToken.Type == token.TOKEN_ASSIGN {
 		p.nextToken()
 		stmt.Value = p.parseExpression(LOWEST)
-		p.nextToken()
+		p.expectSemicolon()
 		return stmt
 	} else if p.curToken.Type == token.TOKEN_SEMICOLON {
 		return stmt
 	} else {
-		p.errors = append(p.errors, "expected = or ; got neither")
+		p.errorf(p.curToken, "expected = or ; got neither")
 		return nil
 	}
 }
 
-func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+func (p *Parser) parseLabeledStatement() ast.Statement {
+	label := &ast.IdentifierExpression{
+		Token: p.curToken,
+		Value: p.curToken.Value,
+	}
+	p.nextToken()
+	p.nextToken()
+	switch p.curToken.Type {
+	case token.TOKEN_WHILE:
+		return p.parseWhileStatement(label)
+	case token.TOKEN_FOR:
+		return p.parseForStatement(label)
+	}
+	p.errorf(p.curToken, "label %s must be followed by a loop, got %s", label.Value, p.curToken.Type)
+	return nil
+}
+
+func (p *Parser) parseWhileStatement(label *ast.IdentifierExpression) *ast.WhileStatement {
 	stmt := &ast.WhileStatement{
 		Token: p.curToken,
+		Label: label,
 	}
 	p.nextToken()
 	stmt.Condition = p.parseExpression(LOWEST)
-	stmt.Body = *p.parseBlockStatement()
+	if !p.expectPeek(token.TOKEN_LCURLY) {
+		return nil
+	}
+	stmt.Body = *p.parseLoopBody(label)
+	return stmt
+}
+
+func (p *Parser) parseForStatement(label *ast.IdentifierExpression) ast.Statement {
+	forToken := p.curToken
+	p.nextToken()
+	if p.curTokenIs(token.IDENTIFIER) && p.peekTokenIs(token.TOKEN_IN) {
+		return p.parseForInStatement(forToken, label)
+	}
+
+	stmt := &ast.ForStatement{Token: forToken, Label: label}
+	if !p.curTokenIs(token.TOKEN_SEMICOLON) {
+		errors := len(p.errors)
+		stmt.Init = p.parseStatement()
+		if !p.curTokenIs(token.TOKEN_SEMICOLON) {
+			// the initializer may have reported the missing ; itself, and left
+			// panic mode as a statement of its own would
+			if len(p.errors) == errors {
+				p.errorf(p.curToken, "expected ; after for loop initializer, got %s", p.curToken.Type)
+			}
+			p.panicking = true
+			p.skipLoopBody()
+			return nil
+		}
+	}
+	p.nextToken()
+	if !p.curTokenIs(token.TOKEN_SEMICOLON) {
+		stmt.Condition = p.parseExpression(LOWEST)
+		if !p.expectPeek(token.TOKEN_SEMICOLON) {
+			return nil
+		}
+	}
+	p.nextToken()
+	if !p.curTokenIs(token.TOKEN_LCURLY) {
+		stmt.Post = p.parseSimpleStatement()
+		if !p.expectPeek(token.TOKEN_LCURLY) {
+			return nil
+		}
+	}
+	stmt.Body = p.parseLoopBody(label)
+	return stmt
+}
+
+// skipLoopBody advances past the body of a for loop whose header failed to
+// parse, so that the rest of the header is not parsed as statements of its own.
+func (p *Parser) skipLoopBody() {
+	for !p.curTokenIs(token.TOKEN_LCURLY) {
+		if p.peekTokenIs(token.TOKEN_RCURLY) || p.peekTokenIs(token.EOF) {
+			return
+		}
+		p.nextToken()
+	}
+	p.skipToClosingBrace()
+}
+
+func (p *Parser) parseForInStatement(forToken token.Token, label *ast.IdentifierExpression) ast.Statement {
+	stmt := &ast.ForInStatement{Token: forToken, Label: label}
+	stmt.Variable = &ast.IdentifierExpression{
+		Token: p.curToken,
+		Value: p.curToken.Value,
+	}
+	p.nextToken()
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.TOKEN_LCURLY) {
+		return nil
+	}
+	stmt.Body = p.parseLoopBody(label)
 	return stmt
 }
 
-// TODO: implement IF ... ELSE IF .... ELSE
+func (p *Parser) parseLoopBody(label *ast.IdentifierExpression) *ast.BlockStatement {
+	name := ""
+	if label != nil {
+		name = label.Value
+	}
+	p.loops = append(p.loops, name)
+	defer func() { p.loops = p.loops[:len(p.loops)-1] }()
+	return p.parseBlockStatement()
+}
+
+func (p *Parser) parseBreakStatement() ast.Statement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+	label, ok := p.parseLoopControl()
+	if !ok {
+		return nil
+	}
+	stmt.Label = label
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() ast.Statement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+	label, ok := p.parseLoopControl()
+	if !ok {
+		return nil
+	}
+	stmt.Label = label
+	return stmt
+}
+
+// parseLoopControl parses the optional label of a break or continue statement
+// and checks that the statement is nested inside a matching loop.
+func (p *Parser) parseLoopControl() (*ast.IdentifierExpression, bool) {
+	keyword := p.curToken
+	var label *ast.IdentifierExpression
+	if p.peekTokenIs(token.IDENTIFIER) {
+		p.nextToken()
+		label = &ast.IdentifierExpression{
+			Token: p.curToken,
+			Value: p.curToken.Value,
+		}
+	}
+	p.expectSemicolon()
+	if len(p.loops) == 0 {
+		p.errorf(keyword, "%s outside of a loop", keyword.Type)
+		return nil, false
+	}
+	if label != nil && !slices.Contains(p.loops, label.Value) {
+		p.errorf(label.Token, "%s to undefined loop label %s", keyword.Type, label.Value)
+		return nil, false
+	}
+	// the innermost loop with the label is the one left
+	target := len(p.loops) - 1
+	for label != nil && p.loops[target] != label.Value {
+		target--
+	}
+	if target < p.ifLoops {
+		p.errorf(keyword, "%s can not leave an if expression", keyword.Type)
+		return nil, false
+	}
+	return label, true
+}
+
 func (p *Parser) parseIfStatement() *ast.IfStatement {
 	stmt := &ast.IfStatement{
 		Token: p.curToken,
 	}
 	p.nextToken()
 	stmt.Condition = p.parseExpression(LOWEST)
-	p.nextToken()
+	if !p.expectPeek(token.TOKEN_LCURLY) {
+		return nil
+	}
 	stmt.Body = p.parseBlockStatement()
-	if p.peekToken.Type == token.TOKEN_ELSE {
+	if p.peekToken.Type != token.TOKEN_ELSE {
+		return stmt
+	}
+	p.nextToken()
+	if p.peekTokenIs(token.TOKEN_IF) {
 		p.nextToken()
-		stmt.Alternative = p.parseBlockStatement()
+		elseIf := p.parseIfStatement()
+		if elseIf == nil {
+			return nil
+		}
+		stmt.Alternative = &ast.BlockStatement{
+			Token:      elseIf.Token,
+			Statements: []ast.Statement{elseIf},
+		}
+		return stmt
+	}
+	if !p.expectPeek(token.TOKEN_LCURLY) {
+		return nil
+	}
+	stmt.Alternative = p.parseBlockStatement()
+	return stmt
+}
+
+func (p *Parser) parseIfExpression() ast.Expression {
+	inIfExpression, ifLoops := p.inIfExpression, p.ifLoops
+	p.inIfExpression, p.ifLoops = true, len(p.loops)
+	defer func() { p.inIfExpression, p.ifLoops = inIfExpression, ifLoops }()
+	stmt := p.parseIfStatement()
+	if stmt == nil {
+		return nil
 	}
 	return stmt
 }
@@ -232,35 +613,223 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	}
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.TOKEN_SEMICOLON) {
+	p.expectSemicolon()
+	if p.inIfExpression {
+		p.errorf(stmt.Token, "return can not leave an if expression")
+		return nil
+	}
+	return stmt
+}
+
+func (p *Parser) parseTryStatement() ast.Statement {
+	stmt := &ast.TryStatement{Token: p.curToken}
+	if !p.expectPeek(token.TOKEN_LCURLY) {
+		return nil
+	}
+	stmt.Body = p.parseBlockStatement()
+	if p.peekTokenIs(token.TOKEN_CATCH) {
+		p.nextToken()
+		if !p.expectPeek(token.TOKEN_LPAREN) || !p.expectPeek(token.IDENTIFIER) {
+			return nil
+		}
+		stmt.Param = &ast.IdentifierExpression{Token: p.curToken, Value: p.curToken.Value}
+		if !p.expectPeek(token.TOKEN_RPAREN) || !p.expectPeek(token.TOKEN_LCURLY) {
+			return nil
+		}
+		stmt.Catch = p.parseBlockStatement()
+	}
+	if p.peekTokenIs(token.TOKEN_FINALLY) {
+		p.nextToken()
+		if !p.expectPeek(token.TOKEN_LCURLY) {
+			return nil
+		}
+		stmt.Finally = p.parseBlockStatement()
+	}
+	if stmt.Catch == nil && stmt.Finally == nil {
+		p.errorf(p.peekToken, "expected catch or finally after try block, got %s", p.peekToken.Type)
+		return nil
+	}
+	return stmt
+}
+
+func (p *Parser) parseThrowStatement() ast.Statement {
+	stmt := &ast.ThrowStatement{Token: p.curToken}
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+	p.expectSemicolon()
+	return stmt
+}
+
+func (p *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	stmt.Path = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Value}
+	if !p.expectPeek(token.TOKEN_AS) {
+		return nil
+	}
+	if !p.expectPeek(token.IDENTIFIER) {
+		return nil
+	}
+	stmt.Name = &ast.IdentifierExpression{Token: p.curToken, Value: p.curToken.Value}
+	p.expectSemicolon()
+	return stmt
+}
+
+func (p *Parser) parseStructStatement() ast.Statement {
+	stmt := &ast.StructStatement{Token: p.curToken}
+	if !p.expectPeek(token.IDENTIFIER) {
+		return nil
+	}
+	stmt.Identifier = p.curToken
+	if !p.expectPeek(token.TOKEN_LCURLY) {
+		return nil
+	}
+	for !p.peekTokenIs(token.TOKEN_RCURLY) {
 		p.nextToken()
+		field := p.parseParameter()
+		if field.Variadic {
+			p.errorf(field.Token, "struct field %s can not be variadic", field.Value)
+			p.skipToClosingBrace()
+			return nil
+		}
+		if n := len(stmt.Fields); n > 0 && stmt.Fields[n-1].Default != nil && field.Default == nil {
+			p.errorf(field.Token, "field %s without a default value follows field %s with one", field.Value, stmt.Fields[n-1].Value)
+			p.skipToClosingBrace()
+			return nil
+		}
+		stmt.Fields = append(stmt.Fields, field)
+		// a trailing comma is allowed
+		if !p.peekTokenIs(token.TOKEN_RCURLY) && !p.expectPeek(token.TOKEN_COMMA) {
+			// a field whose default failed to parse can end on the closing }
+			if !p.curTokenIs(token.TOKEN_RCURLY) {
+				p.skipToClosingBrace()
+			}
+			return nil
+		}
 	}
+	p.nextToken()
 	return stmt
 }
 
+func (p *Parser) parseClassStatement() ast.Statement {
+	stmt := &ast.ClassStatement{Token: p.curToken}
+	if !p.expectPeek(token.IDENTIFIER) {
+		return nil
+	}
+	stmt.Identifier = p.curToken
+	if p.peekTokenIs(token.TOKEN_LPAREN) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENTIFIER) {
+			return nil
+		}
+		stmt.Parent = &ast.IdentifierExpression{Token: p.curToken, Value: p.curToken.Value}
+		if !p.expectPeek(token.TOKEN_RPAREN) {
+			return nil
+		}
+	}
+	if !p.expectPeek(token.TOKEN_LCURLY) {
+		return nil
+	}
+	for !p.peekTokenIs(token.TOKEN_RCURLY) && !p.peekTokenIs(token.EOF) {
+		if !p.expectPeek(token.TOKEN_FUN) {
+			p.skipToClosingBrace()
+			return nil
+		}
+		method, ok := p.parseFunctionDefinition().(*ast.FunctionStatement)
+		if !ok {
+			p.skipToClosingBrace()
+			return nil
+		}
+		if len(method.ParameterList) == 0 || method.ParameterList[0].Variadic {
+			p.errorf(method.Identifier, "method %s must take self as its first parameter", method.Identifier.Value)
+			p.skipToClosingBrace()
+			return nil
+		}
+		stmt.Methods = append(stmt.Methods, method)
+	}
+	if !p.expectPeek(token.TOKEN_RCURLY) {
+		return nil
+	}
+	return stmt
+}
+
+// skipToClosingBrace advances to the } closing the block the parser is in,
+// so that recovering from an error inside the block does not leave it open.
+func (p *Parser) skipToClosingBrace() {
+	depth := 1
+	for !p.peekTokenIs(token.EOF) {
+		p.nextToken()
+		switch p.curToken.Type {
+		case token.TOKEN_LCURLY:
+			depth++
+		case token.TOKEN_RCURLY:
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+func (p *Parser) parseSuperExpression() ast.Expression {
+	return &ast.SuperExpression{Token: p.curToken}
+}
+
 func (p *Parser) parseFunctionDefinition() ast.Statement {
 	stmt := &ast.FunctionStatement{
 		Token: p.curToken,
 	}
 	p.nextToken()
 	if p.curToken.Type != token.IDENTIFIER {
-		p.errors = append(p.errors, "function definition missing identifier")
+		p.errorf(p.curToken, "function definition missing identifier")
 		return nil
 	}
 	stmt.Identifier = p.curToken
-	p.nextToken()
-
+	if !p.expectPeek(token.TOKEN_LPAREN) {
+		return nil
+	}
 	stmt.ParameterList = p.parseFunctionParameterList()
-
+	if stmt.ParameterList == nil {
+		return nil
+	}
+	stmt.ReturnType = p.parseReturnType()
 	p.nextToken()
-
 	if p.curToken.Type != token.TOKEN_LCURLY {
-		p.errors = append(p.errors, fmt.Sprintf("expected {, got %s", p.curToken.Type))
+		p.errorf(p.curToken, "expected {, got %s", p.curToken.Type)
+		return nil
 	}
+	stmt.Body = p.parseFunctionBody()
+	return stmt
+}
 
-	stmt.Body = p.parseBlockStatement()
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	lit := &ast.FunctionLiteral{
+		Token: p.curToken,
+	}
+	if !p.expectPeek(token.TOKEN_LPAREN) {
+		return nil
+	}
+	lit.ParameterList = p.parseFunctionParameterList()
+	if lit.ParameterList == nil {
+		return nil
+	}
+	lit.ReturnType = p.parseReturnType()
+	if !p.expectPeek(token.TOKEN_LCURLY) {
+		return nil
+	}
+	lit.Body = p.parseFunctionBody()
+	return lit
+}
 
-	return stmt
+func (p *Parser) parseFunctionBody() *ast.BlockStatement {
+	// loops and if expressions do not reach into the functions declared
+	// inside them
+	loops, inIfExpression, ifLoops := p.loops, p.inIfExpression, p.ifLoops
+	p.loops, p.inIfExpression, p.ifLoops = nil, false, 0
+	defer func() { p.loops, p.inIfExpression, p.ifLoops = loops, inIfExpression, ifLoops }()
+	return p.parseBlockStatement()
 }
 
 func (p *Parser) parseFunctionParameterList() []ast.IdentifierExpression {
@@ -271,19 +840,21 @@ func (p *Parser) parseFunctionParameterList() []ast.IdentifierExpression {
 	}
 
 	p.nextToken()
-	ident := ast.IdentifierExpression{
-		Token: p.curToken,
-		Value: p.curToken.Value,
-	}
-	paramList = append(paramList, ident)
+	paramList = append(paramList, p.parseParameter())
 	for p.peekToken.Type == token.TOKEN_COMMA {
+		last := paramList[len(paramList)-1]
+		if last.Variadic {
+			p.errorf(last.Token, "variadic parameter %s must be the last parameter", last.Value)
+			return nil
+		}
 		p.nextToken()
 		p.nextToken()
-		ident := ast.IdentifierExpression{
-			Token: p.curToken,
-			Value: p.curToken.Value,
+		param := p.parseParameter()
+		if last.Default != nil && param.Default == nil && !param.Variadic {
+			p.errorf(param.Token, "parameter %s without a default value follows parameter %s with one", param.Value, last.Value)
+			return nil
 		}
-		paramList = append(paramList, ident)
+		paramList = append(paramList, param)
 	}
 
 	if !p.expectPeek(token.TOKEN_RPAREN) {
@@ -293,33 +864,111 @@ func (p *Parser) parseFunctionParameterList() []ast.IdentifierExpression {
 	return paramList
 }
 
+// parseParameter parses a parameter name preceded by an optional type and the
+// ... of a variadic parameter, and followed by an optional default value.
+func (p *Parser) parseParameter() ast.IdentifierExpression {
+	param := ast.IdentifierExpression{}
+	if p.curIsTypeToken() && (p.peekTokenIs(token.IDENTIFIER) || p.peekTokenIs(token.TOKEN_ELLIPSIS)) {
+		param.Type = p.curToken
+		p.nextToken()
+	}
+	if p.curTokenIs(token.TOKEN_ELLIPSIS) {
+		param.Variadic = true
+		p.nextToken()
+	}
+	if !p.curTokenIs(token.IDENTIFIER) {
+		p.errorf(p.curToken, "expected parameter name, got %s", p.curToken.Type)
+	}
+	param.Token = p.curToken
+	param.Value = p.curToken.Value
+	if p.peekTokenIs(token.TOKEN_ASSIGN) {
+		if param.Variadic {
+			p.errorf(p.peekToken, "variadic parameter %s can not have a default value", param.Value)
+			return param
+		}
+		p.nextToken()
+		p.nextToken()
+		param.Default = p.parseExpression(LOWEST)
+	}
+	return param
+}
+
+// parseReturnType parses the optional return type that follows the parameter
+// list of a function, leaving the zero token when there is none.
+func (p *Parser) parseReturnType() token.Token {
+	if !isTypeToken(p.peekToken.Type) {
+		return token.Token{}
+	}
+	p.nextToken()
+	return p.curToken
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	stmt := &ast.BlockStatement{Token: p.curToken}
 	stmt.Statements = []ast.Statement{}
 	p.nextToken()
 	for p.curToken.Type != token.TOKEN_RCURLY && p.curToken.Type != token.EOF {
-		parsedStmt := p.parseStatement()
+		parsedStmt := p.parseNextStatement()
 		if parsedStmt != nil {
 			stmt.Statements = append(stmt.Statements, parsedStmt)
 		}
 		p.nextToken()
 	}
+	if p.curTokenIs(token.EOF) {
+		p.errorf(stmt.Token, "unclosed {, expected } before end of input")
+	}
+	return stmt
+}
+
+func (p *Parser) parseExpressionStatement() ast.Statement {
+	stmt := p.parseSimpleStatement()
+	p.expectSemicolon()
 	return stmt
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+// parseSimpleStatement parses an expression or assignment statement without
+// the ; that ends it, as used for the post statement of a for loop.
+func (p *Parser) parseSimpleStatement() ast.Statement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.TOKEN_SEMICOLON) {
-		p.nextToken()
+	if _, ok := compoundAssignments[p.peekToken.Type]; ok || p.peekTokenIs(token.TOKEN_ASSIGN) {
+		return p.parseAssignStatement(stmt.Expression)
 	}
-	// TODO: throw error here
 	return stmt
 }
 
+func (p *Parser) parseAssignStatement(target ast.Expression) ast.Statement {
+	if !p.checkAssignable(target) {
+		return nil
+	}
+	p.nextToken()
+	stmt := &ast.AssignStatement{
+		Token:    p.curToken,
+		Target:   target,
+		Operator: compoundAssignments[p.curToken.Type],
+	}
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+	return stmt
+}
+
+// checkAssignable reports whether target can be assigned to, recording an
+// error if it can not. Only variables, index and member expressions are
+// assignable. A target that failed to parse is not, but was reported already.
+func (p *Parser) checkAssignable(target ast.Expression) bool {
+	if target == nil {
+		return false
+	}
+	switch target.(type) {
+	case *ast.IdentifierExpression, *ast.IndexExpression, *ast.MemberExpression:
+		return true
+	}
+	p.errorf(target.Pos(), "invalid assignment target %s", target)
+	return false
+}
+
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.errorf(p.curToken, "no prefix parse function for %s found", t)
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -357,6 +1006,10 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	if p, ok := precedences[p.curToken.Type]; ok {
 		prec = p
 	}
+	// ** is right associative: 2 ** 3 ** 2 is 2 ** (3 ** 2)
+	if p.curTokenIs(token.TOKEN_POW) {
+		prec--
+	}
 	p.nextToken()
 	exp.Right = p.parseExpression(prec)
 	return exp
@@ -372,7 +1025,41 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 	return exp
 }
 
+func (p *Parser) parsePrefixUpdateExpression() ast.Expression {
+	exp := &ast.UpdateExpression{
+		Token:    p.curToken,
+		Operator: string(p.curToken.Type),
+	}
+	p.nextToken()
+	exp.Target = p.parseExpression(PREFIX)
+	if exp.Target == nil || !p.checkAssignable(exp.Target) {
+		return nil
+	}
+	return exp
+}
+
+func (p *Parser) parsePostfixUpdateExpression(left ast.Expression) ast.Expression {
+	if !p.checkAssignable(left) {
+		return nil
+	}
+	return &ast.UpdateExpression{
+		Token:    p.curToken,
+		Operator: string(p.curToken.Type),
+		Target:   left,
+		Postfix:  true,
+	}
+}
+
+func (p *Parser) parsePropagateExpression(left ast.Expression) ast.Expression {
+	return &ast.PropagateExpression{Token: p.curToken, Value: left}
+}
+
 func (p *Parser) parseNumberExpression() ast.Expression {
+	// b is a hex digit, so only decimal numbers take the byte suffix
+	hex := strings.HasPrefix(p.curToken.Value, "0x") || strings.HasPrefix(p.curToken.Value, "0X")
+	if strings.HasSuffix(p.curToken.Value, "b") && !hex {
+		return p.parseByteNumber()
+	}
 	if floatExp := p.parseFloatNumber(); floatExp != nil {
 		return floatExp
 	}
@@ -383,10 +1070,7 @@ func (p *Parser) parseNumberExpression() ast.Expression {
 		lit.Value = valueInt
 		return lit
 	}
-	// TODO: parse byte
-
-	msg := fmt.Sprintf("could not parse %q as integer, float or byte, %s", p.curToken.Value, err)
-	p.errors = append(p.errors, msg)
+	p.errorf(p.curToken, "could not parse %q as integer, float or byte, %s", p.curToken.Value, err)
 	return nil
 }
 
@@ -402,6 +1086,19 @@ func (p *Parser) parseFloatNumber() ast.Expression {
 	return nil
 }
 
+func (p *Parser) parseByteNumber() ast.Expression {
+	value, err := strconv.ParseUint(strings.TrimSuffix(p.curToken.Value, "b"), 0, 8)
+	if err != nil {
+		p.errorf(p.curToken, "could not parse %q as byte, %s", p.curToken.Value, err)
+		return nil
+	}
+	return &ast.ByteLiteral{Token: p.curToken, Value: byte(value)}
+}
+
+func (p *Parser) parseCharExpression() ast.Expression {
+	return &ast.ByteLiteral{Token: p.curToken, Value: p.curToken.Value[0]}
+}
+
 func (p *Parser) parseBoolExpression() ast.Expression {
 	lit := &ast.BoolLiteral{Token: p.curToken}
 	if p.curToken.Value == "true" {
@@ -422,6 +1119,12 @@ func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.IdentifierExpression{Token: p.curToken, Value: p.curToken.Value}
 }
 
+// parseTypeIdentifier turns a type keyword used in an expression, as in
+// int("42"), into the identifier of the matching conversion function.
+func (p *Parser) parseTypeIdentifier() ast.Expression {
+	return &ast.IdentifierExpression{Token: p.curToken, Value: string(p.curToken.Type)}
+}
+
 func (p *Parser) parseGroupExpression() ast.Expression {
 	p.nextToken()
 	exp := p.parseExpression(LOWEST)
@@ -450,6 +1153,38 @@ func (p *Parser) parseArrayExpression() ast.Expression {
 	return exp
 }
 
+// startsHashLiteral reports whether the { at the start of a statement opens a
+// hash literal rather than a block. Blocks win unless the brace is followed by
+// a literal key and a colon; `{ name: ...` is a labelled loop inside a block.
+func (p *Parser) startsHashLiteral() bool {
+	switch p.peekToken.Type {
+	case token.STRING, token.NUMBER, token.TOKEN_TRUE, token.TOKEN_FALSE:
+		return p.peekSecondTokenIs(token.TOKEN_COLON)
+	}
+	return false
+}
+
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken}
+	for !p.peekTokenIs(token.TOKEN_RCURLY) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+		if !p.expectPeek(token.TOKEN_COLON) {
+			return nil
+		}
+		p.nextToken()
+		hash.Keys = append(hash.Keys, key)
+		hash.Values = append(hash.Values, p.parseExpression(LOWEST))
+		if !p.peekTokenIs(token.TOKEN_RCURLY) && !p.expectPeek(token.TOKEN_COMMA) {
+			return nil
+		}
+	}
+	if !p.expectPeek(token.TOKEN_RCURLY) {
+		return nil
+	}
+	return hash
+}
+
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{
 		Token: p.curToken,
@@ -461,35 +1196,67 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
-func (p *Parser) parseCallExpression(left ast.Expression) ast.Expression {
-	funIdent, ok := left.(*ast.IdentifierExpression)
-	if !ok {
-		p.errors = append(p.errors, "invalid function identifier")
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{
+		Token:  p.curToken,
+		Object: left,
+	}
+	if !p.expectPeek(token.IDENTIFIER) {
 		return nil
 	}
+	exp.Member = &ast.IdentifierExpression{Token: p.curToken, Value: p.curToken.Value}
+	return exp
+}
+
+func (p *Parser) parseCallExpression(left ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{
 		Token:             p.curToken,
-		FunctionIdentifer: funIdent,
+		FunctionIdentifer: left,
 	}
 	if p.peekToken.Type == token.TOKEN_RPAREN {
 		p.nextToken()
 		return exp
 	}
 	p.nextToken()
-	exp.Parameters = append(exp.Parameters, p.parseExpression(LOWEST))
+	exp.Parameters = append(exp.Parameters, p.parseArgument())
 	for p.peekToken.Type == token.TOKEN_COMMA {
 		p.nextToken()
 		p.nextToken()
-		exp.Parameters = append(exp.Parameters, p.parseExpression(LOWEST))
+		arg := p.parseArgument()
+		_, named := arg.(*ast.NamedArgument)
+		if _, lastNamed := exp.Parameters[len(exp.Parameters)-1].(*ast.NamedArgument); lastNamed && !named && arg != nil {
+			p.errorf(arg.Pos(), "positional argument follows named argument")
+			return nil
+		}
+		exp.Parameters = append(exp.Parameters, arg)
 	}
 	if p.peekToken.Type != token.TOKEN_RPAREN {
-		p.errors = append(p.errors, "expected )")
+		p.errorf(p.peekToken, "expected ), got %s", p.peekToken.Type)
 		return nil
 	}
 	p.nextToken()
 	return exp
 }
 
-func (p *Parser) Errors() []string {
+// parseArgument parses an argument of a call, which may spread an array or
+// name the parameter it is passed to.
+func (p *Parser) parseArgument() ast.Expression {
+	if p.curTokenIs(token.IDENTIFIER) && p.peekTokenIs(token.TOKEN_ASSIGN) {
+		arg := &ast.NamedArgument{Token: p.curToken, Name: p.curToken.Value}
+		p.nextToken()
+		p.nextToken()
+		arg.Value = p.parseExpression(LOWEST)
+		return arg
+	}
+	if !p.curTokenIs(token.TOKEN_ELLIPSIS) {
+		return p.parseExpression(LOWEST)
+	}
+	exp := &ast.SpreadExpression{Token: p.curToken}
+	p.nextToken()
+	exp.Value = p.parseExpression(LOWEST)
+	return exp
+}
+
+func (p *Parser) Errors() []*Error {
 	return p.errors
 }
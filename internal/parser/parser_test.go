@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"interpreter/internal/ast"
 	"interpreter/internal/lexer"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -118,6 +119,73 @@ func TestIfElseStatement(t *testing.T) {
 	}
 }
 
+func TestElseIfStatement(t *testing.T) {
+	input := `
+	if a == 1 {
+		x;
+	} else if a == 2 {
+		y;
+	} else {
+		z;
+	}
+	`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("exp not *ast.IfStatement. got=%T", program.Statements[0])
+	}
+	elseIf := stmt.ElseIf()
+	if elseIf == nil {
+		t.Fatalf("alternative is not an else if. got=%s", stmt.Alternative)
+	}
+	if elseIf.Condition.String() != "(a == 2)" {
+		t.Fatalf("else if condition not (a == 2). got=%s", elseIf.Condition)
+	}
+	if elseIf.Alternative == nil || elseIf.ElseIf() != nil {
+		t.Fatalf("expected a final else block. got=%s", elseIf.Alternative)
+	}
+	expected := "if (a == 1) {\n\tx;\n} else if (a == 2) {\n\ty;\n} else {\n\tz;\n}"
+	if stmt.String() != expected {
+		t.Fatalf("stmt not %q. got=%q", expected, stmt.String())
+	}
+}
+
+func TestIfExpression(t *testing.T) {
+	input := `var x = if a > b { a } else { b };`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+	stmt := program.Statements[0].(*ast.VarStatement)
+	if _, ok := stmt.Value.(*ast.IfStatement); !ok {
+		t.Fatalf("value not *ast.IfStatement. got=%T", stmt.Value)
+	}
+	expected := "var x = if (a > b) {\n\ta;\n} else {\n\tb;\n};"
+	if stmt.String() != expected {
+		t.Fatalf("stmt not %q. got=%q", expected, stmt.String())
+	}
+
+	// control flow that stays inside the if expression is allowed
+	for _, input := range []string{
+		"var x = if c { while d { break; } 1 } else { 0 };",
+		"while x { var y = if c { fun() { return 1; } } else { 0 }; if c { break; } }",
+		"fun f() { if c { return 1; } return 2; }",
+	} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		checkParserErrors(t, p)
+	}
+}
+
 // TODO: expand block statement tests when new stmts/expr get implemented
 // TODO: nested block statements
 func TestBlockStatement(t *testing.T) {
@@ -172,6 +240,84 @@ func TestWhileStatement(t *testing.T) {
 
 }
 
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"for var i = 0; i < 10; i = i + 1 { print(i); }", "for var i = 0; (i < 10); i = (i + 1) {\n\t(print(i));\n}"},
+		{"for ; i < 10; { i = i + 1; }", "for ; (i < 10); {\n\ti = (i + 1);\n}"},
+		{"for i = 0; ; i = i + 1 { }", "for i = 0; ; i = (i + 1) {\n}"},
+		{"for x in [1, 2, 3] { print(x); }", "for x in [1 2 3] {\n\t(print(x));\n}"},
+		{"for x in xs { }", "for x in xs {\n}"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+}
+
+func TestLoopControlStatements(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"while x { break; }", "while (x) {\n\tbreak;\n}"},
+		{"for x in xs { continue; }", "for x in xs {\n\tcontinue;\n}"},
+		{"outer: while x { while y { break outer; } }", "outer: while (x) {\n\twhile (y) {\n\tbreak outer;\n}\n}"},
+		{"outer: for var i = 0; i < 3; i = i + 1 { continue outer; }", "outer: for var i = 0; (i < 3); i = (i + 1) {\n\tcontinue outer;\n}"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+}
+
+func TestLoopControlErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"break;", "break outside of a loop"},
+		{"if x { continue; }", "continue outside of a loop"},
+		{"while x { fun f() { break; } }", "break outside of a loop"},
+		{"outer: while x { break inner; }", "break to undefined loop label inner"},
+		{"outer: var x = 1;", "label outer must be followed by a loop, got var"},
+		{"var x = if true { return 1; };", "return can not leave an if expression"},
+		{"fun f() { print(if x { return 1; } else { 2 }); }", "return can not leave an if expression"},
+		{"fun f() { var x = if a { if b { return 1; } 2 } else { 3 }; }", "return can not leave an if expression"},
+		{"while x { var y = if c { break; } else { 0 }; }", "break can not leave an if expression"},
+		{"outer: while x { var y = if c { while d { continue outer; } 1 } else { 0 }; }", "continue can not leave an if expression"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("expected parser error for %q", tt.input)
+		}
+		if p.Errors()[0].Message != tt.expectedError {
+			t.Errorf("error not %q. got=%q", tt.expectedError, p.Errors()[0])
+		}
+	}
+}
+
 // TODO: check return type, parameters, function name
 func TestFunctionDefinition(t *testing.T) {
 
@@ -198,6 +344,227 @@ func TestFunctionDefinition(t *testing.T) {
 	}
 }
 
+func TestFunctionLiteral(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"var f = fun(a, b) { return a; };", "var f = fun(a, b) {\n\treturn a;\n};"},
+		{"fun() { }();", "(fun() {\n}());"},
+		{"apply(fun(x) { x; }, 2);", "(apply(fun(x) {\n\tx;\n}, 2));"},
+		{"make()(1);", "((make())(1));"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+}
+
+func TestHashLiteral(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{`var h = {"a": 1, "b": 2 + 3};`, "var h = {a: 1, b: (2 + 3)};"},
+		{`var h = {};`, "var h = {};"},
+		{`var h = {1: [1], true: {"x": y}};`, "var h = {1: [1], true: {x: y}};"},
+		{`{"a": 1}["a"];`, "({a: 1}[a]);"},
+		{`{ a; }`, "\n\ta;\n"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+}
+
+func TestAssignStatement(t *testing.T) {
+	input := "var x = 1; x = x + 1;"
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+	if !testVarStatement(t, program.Statements[0], "x") {
+		return
+	}
+	stmt, ok := program.Statements[1].(*ast.AssignStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.AssignStatement. got=%T", program.Statements[1])
+	}
+	if !testIdentifier(t, stmt.Target, "x") {
+		return
+	}
+	if stmt.String() != "x = (x + 1);" {
+		t.Fatalf("stmt not %q. got=%q", "x = (x + 1);", stmt.String())
+	}
+}
+
+func TestIndexAssignStatement(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"a[0] = 1;", "(a[0]) = 1;"},
+		{`h["k"] = v + 1;`, "(h[k]) = (v + 1);"},
+		{"grid[y][x] = 1;", "((grid[y])[x]) = 1;"},
+		{"f()[i + 1] = [1];", "((f())[(i + 1)]) = [1];"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if _, ok := program.Statements[0].(*ast.AssignStatement); !ok {
+			t.Fatalf("stmt not *ast.AssignStatement. got=%T", program.Statements[0])
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+
+	l := lexer.New("f() = 1;")
+	p := New(l)
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || p.Errors()[0].Message != "invalid assignment target (f())" {
+		t.Fatalf("expected invalid assignment target error. got=%q", p.Errors())
+	}
+}
+
+func TestByteLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected byte
+		output   string
+	}{
+		{"'a';", 'a', "'a';"},
+		{`'\n';`, '\n', `'\n';`},
+		{"97b;", 97, "97b;"},
+		{`'\x41';`, 0x41, "'A';"},
+		{`'\xff';`, 0xff, `'\xff';`},
+		{`'\x00';`, 0, `'\x00';`},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		lit, ok := stmt.Expression.(*ast.ByteLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.ByteLiteral. got=%T", stmt.Expression)
+		}
+		if lit.Value != tt.expected {
+			t.Errorf("lit.Value not %d. got=%d", tt.expected, lit.Value)
+		}
+		if stmt.String() != tt.output {
+			t.Errorf("stmt not %q. got=%q", tt.output, stmt.String())
+		}
+	}
+
+	for input, expected := range map[string]int64{"0xab;": 0xab, "0x1b;": 0x1b, "0xb;": 0xb, "0x41b;": 0x41b} {
+		p := New(lexer.New(input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		lit, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok || lit.Value != expected {
+			t.Errorf("%s is not the integer %d. got=%s", input, expected, stmt.Expression)
+		}
+	}
+
+	l := lexer.New("256b;")
+	p := New(l)
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || !strings.HasPrefix(p.Errors()[0].Message, `could not parse "256b" as byte`) {
+		t.Fatalf("expected byte range error. got=%q", p.Errors())
+	}
+}
+
+func TestTypedDeclarations(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"int x = 5;", "int x = 5;"},
+		{"float f;", "float f;"},
+		{"string s = string(5);", "string s = (string(5));"},
+		{"fun add(int a, b) int { return a + b; }", "func add(int a, b) int \n\treturn (a + b);\n"},
+		{"var f = fun(string s) bool { return true; };", "var f = fun(string s) bool {\n\treturn true;\n};"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+}
+
+func TestUpdateExpressions(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"i++;", "(i++);"},
+		{"--i;", "(--i);"},
+		{"a[0]++;", "((a[0])++);"},
+		{"-i++;", "(-(i++));"},
+		{"x = i++ + ++j;", "x = ((i++) + (++j));"},
+		{"i += 2;", "i += 2;"},
+		{"a[i] -= 1 * 2;", "(a[i]) -= (1 * 2);"},
+		{"s *= 3;", "s *= 3;"},
+		{"s /= 3;", "s /= 3;"},
+		{"s %= 3;", "s %= 3;"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+
+	for _, input := range []string{"5++;", "++f();", "f() += 1;"} {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 || !strings.HasPrefix(p.Errors()[0].Message, "invalid assignment target") {
+			t.Fatalf("expected invalid assignment target error for %q. got=%q", input, p.Errors())
+		}
+	}
+}
+
 // TODO: consider merging prefix and infix expression tests
 func TestInfixExpressions(t *testing.T) { // TODO: add more tests
 	tests := []struct {
@@ -211,6 +578,13 @@ func TestInfixExpressions(t *testing.T) { // TODO: add more tests
 		{"b = 5 + 2 / 3 - 6 * 9 - a(1);", "b = (((5 + (2 / 3)) - (6 * 9)) - (a(1)));"},
 		{"var j = 9123 - a[81] * (12 - 3);", "var j = (9123 - ((a[81]) * (12 - 3)));"},
 		{"a and b or c;", "((a and b) or c);"},
+		{"a == b and c != d;", "((a == b) and (c != d));"},
+		{"a < b == c >= d;", "((a < b) == (c >= d));"},
+		{"a % b * c;", "((a % b) * c);"},
+		{"2 ** 3 ** 2;", "(2 ** (3 ** 2));"},
+		{"2 * 3 ** 2;", "(2 * (3 ** 2));"},
+		{"a | b & c ^ d;", "((a | (b & c)) ^ d);"},
+		{"1 << 2 + 3 >> 1;", "((1 << 2) + (3 >> 1));"},
 	}
 	for _, tt := range tests {
 		l := lexer.New(tt.input)
@@ -238,6 +612,8 @@ func TestPrefixExpressions(t *testing.T) {
 		{"-5;", "(-5);"},
 		{"-5 + 2;", "((-5) + 2);"},
 		{"-(5 + 2);", "(-(5 + 2));"},
+		{"~5 & 3;", "((~5) & 3);"},
+		{"-2 ** 2;", "(-(2 ** 2));"},
 	}
 	for _, tt := range tests {
 		l := lexer.New(tt.input)
@@ -400,3 +776,482 @@ func testIntegerArrayLiteral(t *testing.T, al ast.Expression, value []int) bool
 	}
 	return true
 }
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		position string
+		message  string
+	}{
+		{"var x = ;", "main.m:1:9", "no prefix parse function for ; found"},
+		{"var a = 1;\nvar = 2;", "main.m:2:5", "expected identifier"},
+		{"fun f() {\n\tbreak;\n}", "main.m:2:2", "break outside of a loop"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.NewFile("main.m", tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("expected errors for %q", tt.input)
+		}
+		err := p.Errors()[0]
+		if err.Token.Position() != tt.position {
+			t.Errorf("wrong position for %q. expected=%q, got=%q", tt.input, tt.position, err.Token.Position())
+		}
+		if err.Message != tt.message {
+			t.Errorf("wrong message for %q. expected=%q, got=%q", tt.input, tt.message, err.Message)
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"var = 1;\nvar b = 2;\nprint(b +);", []string{
+			"1:5: expected identifier",
+			"3:10: no prefix parse function for ) found",
+		}},
+		{"var a = 1 var b = 2;\nprint(a) print(b);", []string{
+			"1:11: expected ; at end of statement, got var",
+			"2:10: expected ; at end of statement, got IDENTIFIER",
+		}},
+		{"if (1 + ) { print(1); }\nvar x = ;", []string{
+			"1:9: no prefix parse function for ) found",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"fun f() {\n\tvar = 1;\n\treturn 2\n}\n}", []string{
+			"2:6: expected identifier",
+			"5:1: unexpected } without matching {",
+		}},
+		{"while true {\n\tprint(1);", []string{
+			"1:12: unclosed {, expected } before end of input",
+		}},
+		{"fun f(a b) { return 1; }\nprint(2);\nvar x = ;", []string{
+			"1:9: expected next token to be ), got IDENTIFIER instead",
+			"3:9: no prefix parse function for ; found",
+		}},
+		{"fun f(...r, a) {}\nvar x = ;", []string{
+			"1:10: variadic parameter r must be the last parameter",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"fun f(a = 1, b) {}\nvar x = ;", []string{
+			"1:14: parameter b without a default value follows parameter a with one",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"fun f() int x { }\nvar x = ;", []string{
+			"1:13: expected {, got IDENTIFIER",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{`var h = {"a" 1, "b": 2};`, []string{
+			"1:14: expected next token to be :, got NUMBER instead",
+		}},
+		{"var f = fun(a b) { return a; };", []string{
+			"1:15: expected next token to be ), got IDENTIFIER instead",
+		}},
+		{"for var i = 0 i < 3; i++ {}\nvar z = ;", []string{
+			"1:15: expected ; at end of statement, got IDENTIFIER",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"for (var i = 0; i < 3; i++) { }\nvar z = ;", []string{
+			"1:6: no prefix parse function for var found",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"struct P { x y }\nvar z = ;", []string{
+			"1:14: expected next token to be ,, got IDENTIFIER instead",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"struct P { ...r }\nvar z = ;", []string{
+			"1:15: struct field r can not be variadic",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"struct P { x = 1, y }\nvar z = ;", []string{
+			"1:19: field y without a default value follows field x with one",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"struct P { x = }\nvar z = ;", []string{
+			"1:16: no prefix parse function for } found",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"class A { fun f() { } }\nvar x = ;", []string{
+			"1:15: method f must take self as its first parameter",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"class A { var y = 1; fun f(self) { } }\nvar x = ;", []string{
+			"1:11: expected next token to be fun, got var instead",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"class A { fun f(self a) { if true { } } }\nvar x = ;", []string{
+			"1:22: expected next token to be ), got IDENTIFIER instead",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"(1 = 2;\nvar x = ;", []string{
+			"1:4: expected next token to be ), got = instead",
+			"2:9: no prefix parse function for ; found",
+		}},
+		{"{ var a = 1 }\nvar b = [1, 2;\nb;", []string{
+			"2:14: expected next token to be ], got ; instead",
+		}},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		var errors []string
+		for _, err := range p.Errors() {
+			errors = append(errors, err.Error())
+		}
+		if !slices.Equal(errors, tt.expected) {
+			t.Errorf("wrong errors for %q.\nexpected=%q\ngot=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestErrorRecoveryKeepsStatements(t *testing.T) {
+	p := New(lexer.New("var a = ;\nvar b = 2;\nprint(b +);\nb;"))
+	program := p.ParseProgram()
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+	for i, expected := range []string{"var b = 2;", "b;"} {
+		if program.Statements[i].String() != expected {
+			t.Errorf("statement %d not %q. got=%q", i, expected, program.Statements[i])
+		}
+	}
+}
+
+func TestLexerErrorsReported(t *testing.T) {
+	input := "var a = 1.2.3;\nvar b = 2 @ 3;\nvar c = \"x"
+	expected := []string{
+		"1:9: malformed number 1.2.3",
+		"2:11: illegal character '@'",
+		"3:9: unterminated string",
+	}
+	p := New(lexer.New(input))
+	p.ParseProgram()
+	var errors []string
+	for _, err := range p.Errors() {
+		errors = append(errors, err.Error())
+	}
+	if !slices.Equal(errors, expected) {
+		t.Errorf("wrong errors.\nexpected=%q\ngot=%q", expected, errors)
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := "// leading\nvar a = { // hash\n\t\"k\": 1\n}; // trailing\na // at the end"
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+}
+
+func TestTryStatement(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"try { f(); } catch (e) { print(e); }", "try {\n\t(f());\n} catch (e) {\n\t(print(e));\n}"},
+		{"try { f(); } finally { g(); }", "try {\n\t(f());\n} finally {\n\t(g());\n}"},
+		{"try { } catch (err) { } finally { }", "try {\n} catch (err) {\n} finally {\n}"},
+		{"throw \"boom\";", "throw boom;"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+}
+
+func TestTryStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { f(); }", "expected catch or finally after try block, got EOF"},
+		{"try { f(); } catch { }", "expected next token to be (, got { instead"},
+		{"try { f(); } catch (1) { }", "expected next token to be IDENTIFIER, got NUMBER instead"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("expected errors for %q", tt.input)
+		}
+		if p.Errors()[0].Message != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors()[0].Message)
+		}
+	}
+}
+
+func TestPropagateExpression(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"read(f)?;", "((read(f))?);"},
+		{"var data = read(f)?;", "var data = ((read(f))?);"},
+		{"a + b?;", "(a + (b?));"},
+		{"-x?;", "(-(x?));"},
+		{"f()?[0];", "(((f())?)[0]);"},
+		{"return check(x)? * 2;", "return (((check(x))?) * 2);"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+}
+
+func TestVariadicFunctions(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"fun f(a, ...rest) { }", "func f(a, ...rest) \n"},
+		{"fun f(int ...nums) int { }", "func f(int ...nums) int \n"},
+		{"var f = fun(...args) { };", "var f = fun(...args) {\n};"},
+		{"f(...args);", "(f(...args));"},
+		{"f(1, ...[2, 3], ...g());", "(f(1, ...[2 3], ...(g())));"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"fun f(...rest, a) { }", "variadic parameter rest must be the last parameter"},
+		{"fun f(...) { }", "expected parameter name, got )"},
+		{"fun f(1) { }", "expected parameter name, got NUMBER"},
+	}
+	for _, tt := range errors {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("expected errors for %q", tt.input)
+		}
+		if p.Errors()[0].Message != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors()[0].Message)
+		}
+	}
+}
+
+func TestDefaultsAndNamedArguments(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"fun connect(host, port = 8080, verbose = false) { }", "func connect(host, port = 8080, verbose = false) \n"},
+		{"fun f(int a, int b = a * 2, ...rest) { }", "func f(int a, int b = (a * 2), ...rest) \n"},
+		{"connect(\"x\", verbose = true);", "(connect(x, verbose = true));"},
+		{"f(port = 1 + 2, host = h);", "(f(port = (1 + 2), host = h));"},
+		{"f(a == 1);", "(f((a == 1)));"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"fun f(a = 1, b) { }", "parameter b without a default value follows parameter a with one"},
+		{"fun f(...rest = 1) { }", "variadic parameter rest can not have a default value"},
+		{"f(a = 1, 2);", "positional argument follows named argument"},
+	}
+	for _, tt := range errors {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("expected errors for %q", tt.input)
+		}
+		if p.Errors()[0].Message != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors()[0].Message)
+		}
+	}
+}
+
+func TestImportStatement(t *testing.T) {
+	p := New(lexer.New(`import "lib/util.mst" as util;`))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.ImportStatement)
+	if !ok {
+		t.Fatalf("statement is not *ast.ImportStatement. got=%T", program.Statements[0])
+	}
+	if stmt.Path.Value != "lib/util.mst" || stmt.Name.Value != "util" {
+		t.Errorf("wrong import. got=%q", stmt.String())
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"import util;", "expected next token to be STRING, got IDENTIFIER instead"},
+		{`import "util.mst";`, "expected next token to be as, got ; instead"},
+		{`import "util.mst" as "u";`, "expected next token to be IDENTIFIER, got STRING instead"},
+	}
+	for _, tt := range errors {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("expected errors for %q", tt.input)
+		}
+		if p.Errors()[0].Message != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors()[0].Message)
+		}
+	}
+}
+
+func TestMemberExpression(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"a.b;", "(a.b);"},
+		{"a.b.c;", "((a.b).c);"},
+		{`"abc".upper();`, "((abc.upper)());"},
+		{"arr.push(1 + 2);", "((arr.push)((1 + 2)));"},
+		{"a[0].b;", "((a[0]).b);"},
+		{"a.b[0];", "((a.b)[0]);"},
+		{"-a.b;", "(-(a.b));"},
+		{"a.f()?;", "(((a.f)())?);"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+
+	p := New(lexer.New("a.1;"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || p.Errors()[0].Message != "expected next token to be IDENTIFIER, got NUMBER instead" {
+		t.Errorf("wrong errors for a.1. got=%v", p.Errors())
+	}
+}
+
+func TestStructStatement(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"struct Point { x, y }", "struct Point { x, y }"},
+		{"struct Point { int x, float y, }", "struct Point { int x, float y }"},
+		{"struct Config { host, port = 80 }", "struct Config { host, port = 80 }"},
+		{"struct Empty { }", "struct Empty {  }"},
+		{"p.x = 1;", "(p.x) = 1;"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"struct { x }", "expected next token to be IDENTIFIER, got { instead"},
+		{"struct Point { x y }", "expected next token to be ,, got IDENTIFIER instead"},
+		{"struct Point { ...xs }", "struct field xs can not be variadic"},
+		{"struct Point { x = 1, y }", "field y without a default value follows field x with one"},
+	}
+	for _, tt := range errors {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("expected errors for %q", tt.input)
+		}
+		if p.Errors()[0].Message != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors()[0].Message)
+		}
+	}
+}
+
+func TestClassStatement(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedOutput string
+	}{
+		{"class Stack { fun push(self, v) { } }", "class Stack { func push(self, v) \n}"},
+		{"class Counter(Stack) { fun init(self) { } fun push(self, v) { super.push(v); } }",
+			"class Counter(Stack) { func init(self) \nfunc push(self, v) \n\t((super.push)(v));\n}"},
+		{"class Empty { }", "class Empty { }"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expectedOutput {
+			t.Errorf("exp not %q. got=%q", tt.expectedOutput, program.Statements[0])
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"class Stack { var x = 1; }", "expected next token to be fun, got var instead"},
+		{"class Stack { fun size() { } }", "method size must take self as its first parameter"},
+		{"class Stack(1) { }", "expected next token to be IDENTIFIER, got NUMBER instead"},
+	}
+	for _, tt := range errors {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("expected errors for %q", tt.input)
+		}
+		if p.Errors()[0].Message != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors()[0].Message)
+		}
+	}
+}
@@ -1,43 +1,77 @@
 package token
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type TokenType string
 
 const (
-	TOKEN_PLUS      = "+"
-	TOKEN_MINUS     = "-"
-	TOKEN_MUL       = "*"
-	TOKEN_DIV       = "/"
-	TOKEN_BANG      = "!"
-	TOKEN_LBRACKET  = "["
-	TOKEN_RBRACKET  = "]"
-	TOKEN_LPAREN    = "("
-	TOKEN_RPAREN    = ")"
-	TOKEN_LCURLY    = "{"
-	TOKEN_RCURLY    = "}"
-	TOKEN_SEMICOLON = ";"
-	TOKEN_COMMA     = ","
-	TOKEN_GT        = ">"
-	TOKEN_LT        = "<"
-	TOKEN_GTE       = ">="
-	TOKEN_LTE       = "<="
-	TOKEN_ASSIGN    = "="
-	TOKEN_EQUAL     = "=="
-	TOKEN_NOT_EQUAL = "!="
+	TOKEN_PLUS         = "+"
+	TOKEN_MINUS        = "-"
+	TOKEN_MUL          = "*"
+	TOKEN_DIV          = "/"
+	TOKEN_MOD          = "%"
+	TOKEN_POW          = "**"
+	TOKEN_BIT_AND      = "&"
+	TOKEN_BIT_OR       = "|"
+	TOKEN_BIT_XOR      = "^"
+	TOKEN_BIT_NOT      = "~"
+	TOKEN_SHIFT_LEFT   = "<<"
+	TOKEN_SHIFT_RIGHT  = ">>"
+	TOKEN_INCREMENT    = "++"
+	TOKEN_DECREMENT    = "--"
+	TOKEN_BANG         = "!"
+	TOKEN_LBRACKET     = "["
+	TOKEN_RBRACKET     = "]"
+	TOKEN_LPAREN       = "("
+	TOKEN_RPAREN       = ")"
+	TOKEN_LCURLY       = "{"
+	TOKEN_RCURLY       = "}"
+	TOKEN_SEMICOLON    = ";"
+	TOKEN_COMMA        = ","
+	TOKEN_COLON        = ":"
+	TOKEN_QUESTION     = "?"
+	TOKEN_ELLIPSIS     = "..."
+	TOKEN_DOT          = "."
+	TOKEN_GT           = ">"
+	TOKEN_LT           = "<"
+	TOKEN_GTE          = ">="
+	TOKEN_LTE          = "<="
+	TOKEN_ASSIGN       = "="
+	TOKEN_PLUS_ASSIGN  = "+="
+	TOKEN_MINUS_ASSIGN = "-="
+	TOKEN_MUL_ASSIGN   = "*="
+	TOKEN_DIV_ASSIGN   = "/="
+	TOKEN_MOD_ASSIGN   = "%="
+	TOKEN_EQUAL        = "=="
+	TOKEN_NOT_EQUAL    = "!="
 
-	TOKEN_FUN    = "fun"
-	TOKEN_NIL    = "nil"
-	TOKEN_IF     = "if"
-	TOKEN_ELSE   = "else"
-	TOKEN_FOR    = "for"
-	TOKEN_WHILE  = "while"
-	TOKEN_RETURN = "return"
-	TOKEN_AND    = "and"
-	TOKEN_OR     = "or"
-	TOKEN_TRUE   = "true"
-	TOKEN_FALSE  = "false"
-	TOKEN_VAR    = "var"
+	TOKEN_FUN      = "fun"
+	TOKEN_NIL      = "nil"
+	TOKEN_IF       = "if"
+	TOKEN_ELSE     = "else"
+	TOKEN_FOR      = "for"
+	TOKEN_IN       = "in"
+	TOKEN_WHILE    = "while"
+	TOKEN_RETURN   = "return"
+	TOKEN_BREAK    = "break"
+	TOKEN_CONTINUE = "continue"
+	TOKEN_AND      = "and"
+	TOKEN_OR       = "or"
+	TOKEN_TRUE     = "true"
+	TOKEN_FALSE    = "false"
+	TOKEN_VAR      = "var"
+	TOKEN_TRY      = "try"
+	TOKEN_CATCH    = "catch"
+	TOKEN_FINALLY  = "finally"
+	TOKEN_THROW    = "throw"
+	TOKEN_IMPORT   = "import"
+	TOKEN_AS       = "as"
+	TOKEN_STRUCT   = "struct"
+	TOKEN_CLASS    = "class"
+	TOKEN_SUPER    = "super"
 
 	TOKEN_STRING = "string"
 	TOKEN_INT    = "int"
@@ -48,6 +82,7 @@ const (
 	NUMBER     = "NUMBER"
 	IDENTIFIER = "IDENTIFIER"
 	STRING     = "STRING"
+	CHAR       = "CHAR"
 	COMMENT    = "COMMENT"
 
 	EOF = "EOF"
@@ -63,23 +98,35 @@ type Token struct {
 }
 
 var keywords = map[string]TokenType{
-	"string": TOKEN_STRING,
-	"int":    TOKEN_INT,
-	"bool":   TOKEN_BOOL,
-	"byte":   TOKEN_BYTE,
-	"float":  TOKEN_FLOAT,
-	"fun":    TOKEN_FUN,
-	"nil":    TOKEN_NIL,
-	"if":     TOKEN_IF,
-	"else":   TOKEN_ELSE,
-	"for":    TOKEN_FOR,
-	"while":  TOKEN_WHILE,
-	"return": TOKEN_RETURN,
-	"and":    TOKEN_AND,
-	"or":     TOKEN_OR,
-	"true":   TOKEN_TRUE,
-	"false":  TOKEN_FALSE,
-	"var":    TOKEN_VAR,
+	"string":   TOKEN_STRING,
+	"int":      TOKEN_INT,
+	"bool":     TOKEN_BOOL,
+	"byte":     TOKEN_BYTE,
+	"float":    TOKEN_FLOAT,
+	"fun":      TOKEN_FUN,
+	"nil":      TOKEN_NIL,
+	"if":       TOKEN_IF,
+	"else":     TOKEN_ELSE,
+	"for":      TOKEN_FOR,
+	"in":       TOKEN_IN,
+	"while":    TOKEN_WHILE,
+	"return":   TOKEN_RETURN,
+	"break":    TOKEN_BREAK,
+	"continue": TOKEN_CONTINUE,
+	"and":      TOKEN_AND,
+	"or":       TOKEN_OR,
+	"true":     TOKEN_TRUE,
+	"false":    TOKEN_FALSE,
+	"var":      TOKEN_VAR,
+	"try":      TOKEN_TRY,
+	"catch":    TOKEN_CATCH,
+	"finally":  TOKEN_FINALLY,
+	"throw":    TOKEN_THROW,
+	"import":   TOKEN_IMPORT,
+	"as":       TOKEN_AS,
+	"struct":   TOKEN_STRUCT,
+	"class":    TOKEN_CLASS,
+	"super":    TOKEN_SUPER,
 }
 
 func LookupIdent(ident string) TokenType {
@@ -92,3 +139,33 @@ func LookupIdent(ident string) TokenType {
 func (t Token) String() string {
 	return fmt.Sprintf("|Type: %s Value: '%s' Position: %d:%d|", t.Type, t.Value, t.Line, t.Col)
 }
+
+// Position returns the location of the token as file:line:col, leaving out
+// the file for tokens that were not read from one.
+func (t Token) Position() string {
+	if t.Filename == "" {
+		return fmt.Sprintf("%d:%d", t.Line, t.Col)
+	}
+	return fmt.Sprintf("%s:%d:%d", t.Filename, t.Line, t.Col)
+}
+
+// Highlight returns the line of source the token was read from with a caret
+// under the token's first character. It returns "" if source has no such line.
+func Highlight(source string, t Token) string {
+	lines := strings.Split(source, "\n")
+	if t.Line < 1 || t.Line > len(lines) {
+		return ""
+	}
+	line := strings.TrimRight(lines[t.Line-1], "\r")
+	var caret strings.Builder
+	for i := 0; i < t.Col-1 && i < len(line); i++ {
+		// keep tabs so the caret lines up however they are displayed
+		if line[i] == '\t' {
+			caret.WriteByte('\t')
+		} else {
+			caret.WriteByte(' ')
+		}
+	}
+	caret.WriteByte('^')
+	return line + "\n" + caret.String()
+}